@@ -7,6 +7,8 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	// zone database for account/template timezones in images without one
+	_ "time/tzdata"
 
 	"bankdash/backend/internal/config"
 	"bankdash/backend/internal/httpx"
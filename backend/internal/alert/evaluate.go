@@ -0,0 +1,221 @@
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/util"
+)
+
+var defaultBudgetThresholds = []float64{80, 100}
+
+// Input is everything the rules look at after one import.
+type Input struct {
+	AccountID string
+	Batch     []domain.Transaction
+	// merchants (lower-cased) already seen on the account before this batch
+	KnownMerchants map[string]bool
+	// the account had no bookings before this batch, so the batch is history
+	FirstImport bool
+	Budgets     []domain.BudgetStatus
+	Now         time.Time
+}
+
+// Evaluate returns the alerts the enabled rules raise for in. Keys are
+// stable, so the caller can drop alerts it already sent.
+func Evaluate(rules []domain.AlertRule, in Input) []domain.Alert {
+	var out []domain.Alert
+	for _, r := range rules {
+		if !r.Enabled || !forAccount(r, in.AccountID) {
+			continue
+		}
+		switch r.Type {
+		case domain.AlertRuleBudget:
+			out = append(out, budgetAlerts(r, in)...)
+		case domain.AlertRuleLargeTx:
+			out = append(out, largeTxAlerts(r, in)...)
+		case domain.AlertRuleNewPayee:
+			out = append(out, newPayeeAlerts(r, in)...)
+		case domain.AlertRuleLowBalance:
+			out = append(out, lowBalanceAlerts(r, in)...)
+		}
+	}
+	return out
+}
+
+func budgetAlerts(r domain.AlertRule, in Input) []domain.Alert {
+	thresholds := append([]float64(nil), r.Thresholds...)
+	if len(thresholds) == 0 {
+		thresholds = defaultBudgetThresholds
+	}
+	sort.Float64s(thresholds)
+
+	var out []domain.Alert
+	for _, st := range in.Budgets {
+		if len(r.BudgetIDs) > 0 && !contains(r.BudgetIDs, st.BudgetID) {
+			continue
+		}
+		// only the highest crossed threshold, e.g. not 80% and 100% at once
+		crossed := -1.0
+		for _, t := range thresholds {
+			if st.PercentUsed >= t {
+				crossed = t
+			}
+		}
+		if crossed < 0 {
+			continue
+		}
+		sev := "warning"
+		if crossed >= 100 {
+			sev = "critical"
+		}
+		out = append(out, domain.Alert{
+			Key:      fmt.Sprintf("%s|%s|%s|%g", r.ID, st.BudgetID, st.PeriodStart.Format("2006-01-02"), crossed),
+			RuleID:   r.ID,
+			Type:     r.Type,
+			Severity: sev,
+			Title:    fmt.Sprintf("Budget %s at %.0f%%", budgetName(st), st.PercentUsed),
+			Message: fmt.Sprintf("%s %s spent of %s (period %s to %s, %s left)",
+				util.FormatMinor(st.SpentCents, st.Currency, "."), st.Currency, util.FormatMinor(st.AvailableCents, st.Currency, "."),
+				st.PeriodStart.Format("2006-01-02"), st.PeriodEnd.AddDate(0, 0, -1).Format("2006-01-02"),
+				util.FormatMinor(st.RemainingCents, st.Currency, ".")),
+			At: in.Now,
+		})
+	}
+	return out
+}
+
+func largeTxAlerts(r domain.AlertRule, in Input) []domain.Alert {
+	// a first import brings the account's history; old bookings are no news
+	if r.AmountCents <= 0 || in.FirstImport {
+		return nil
+	}
+	var out []domain.Alert
+	for _, tx := range in.Batch {
+		if tx.CategoryID == domain.CategoryTransfer || abs64(tx.AmountCents) < r.AmountCents {
+			continue
+		}
+		out = append(out, domain.Alert{
+			Key:       r.ID + "|" + tx.TxUID,
+			RuleID:    r.ID,
+			Type:      r.Type,
+			Severity:  "warning",
+			Title:     fmt.Sprintf("Large transaction: %s %s", util.FormatMinor(tx.AmountCents, tx.Currency, "."), tx.Currency),
+			Message:   fmt.Sprintf("%s on %s: %s", txName(tx), tx.BookingDate.Format("2006-01-02"), tx.Memo),
+			AccountID: tx.AccountID,
+			TxUID:     tx.TxUID,
+			At:        in.Now,
+		})
+	}
+	return out
+}
+
+func newPayeeAlerts(r domain.AlertRule, in Input) []domain.Alert {
+	// first import of an account: everything is new, nothing is news
+	if in.FirstImport {
+		return nil
+	}
+	seen := map[string]bool{}
+	var out []domain.Alert
+	for _, tx := range in.Batch {
+		name := txName(tx)
+		k := strings.ToLower(name)
+		if k == "" || tx.CategoryID == domain.CategoryTransfer || in.KnownMerchants[k] || seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, domain.Alert{
+			Key:       r.ID + "|" + tx.AccountID + "|" + k,
+			RuleID:    r.ID,
+			Type:      r.Type,
+			Severity:  "info",
+			Title:     "New payee: " + name,
+			Message:   fmt.Sprintf("%s %s on %s", util.FormatMinor(tx.AmountCents, tx.Currency, "."), tx.Currency, tx.BookingDate.Format("2006-01-02")),
+			AccountID: tx.AccountID,
+			TxUID:     tx.TxUID,
+			At:        in.Now,
+		})
+	}
+	return out
+}
+
+func lowBalanceAlerts(r domain.AlertRule, in Input) []domain.Alert {
+	tx, ok := LatestBalance(in.Batch)
+	if !ok || *tx.BalanceCents >= r.AmountCents {
+		return nil
+	}
+	return []domain.Alert{{
+		Key:       r.ID + "|" + tx.AccountID + "|" + tx.BookingDate.Format("2006-01-02"),
+		RuleID:    r.ID,
+		Type:      r.Type,
+		Severity:  "critical",
+		Title:     fmt.Sprintf("Low balance on %s: %s %s", tx.AccountID, util.FormatMinor(*tx.BalanceCents, tx.Currency, "."), tx.Currency),
+		Message:   fmt.Sprintf("Balance after %s is below %s", tx.BookingDate.Format("2006-01-02"), util.FormatMinor(r.AmountCents, tx.Currency, ".")),
+		AccountID: tx.AccountID,
+		At:        in.Now,
+	}}
+}
+
+// LatestBalance finds the booking carrying the most recent running balance.
+// Exports list either newest or oldest first; among bookings of the latest
+// day the one nearest the newest end of the file wins.
+func LatestBalance(txs []domain.Transaction) (domain.Transaction, bool) {
+	best := -1
+	for i, tx := range txs {
+		if tx.BalanceCents == nil {
+			continue
+		}
+		if best < 0 || tx.BookingDate.After(txs[best].BookingDate) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return domain.Transaction{}, false
+	}
+	newestFirst := len(txs) > 1 && !txs[0].BookingDate.Before(txs[len(txs)-1].BookingDate)
+	if !newestFirst {
+		for i := best + 1; i < len(txs); i++ {
+			if txs[i].BalanceCents != nil && txs[i].BookingDate.Equal(txs[best].BookingDate) {
+				best = i
+			}
+		}
+	}
+	return txs[best], true
+}
+
+func forAccount(r domain.AlertRule, accountID string) bool {
+	return len(r.AccountIDs) == 0 || contains(r.AccountIDs, accountID)
+}
+
+func budgetName(st domain.BudgetStatus) string {
+	if st.Name != "" {
+		return st.Name
+	}
+	return st.BudgetID
+}
+
+func txName(tx domain.Transaction) string {
+	if tx.Merchant != "" {
+		return tx.Merchant
+	}
+	return strings.TrimSpace(tx.Payee)
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,171 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"bankdash/backend/internal/domain"
+)
+
+var evalNow = time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC)
+
+func day(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+
+func balance(v int64) *int64 { return &v }
+
+func keys(alerts []domain.Alert) []string {
+	var out []string
+	for _, a := range alerts {
+		out = append(out, a.Key)
+	}
+	return out
+}
+
+func TestBudgetAlerts(t *testing.T) {
+	rule := domain.AlertRule{ID: "b", Type: domain.AlertRuleBudget, Enabled: true}
+	status := func(pct float64) domain.BudgetStatus {
+		return domain.BudgetStatus{BudgetID: "food", Name: "Food", Currency: "EUR", PeriodStart: day(1), PeriodEnd: day(1).AddDate(0, 1, 0),
+			AvailableCents: 40000, SpentCents: int64(pct * 400), RemainingCents: 40000 - int64(pct*400), PercentUsed: pct}
+	}
+	tests := []struct {
+		pct      float64
+		key, sev string
+	}{
+		{79.9, "", ""},
+		{80, "b|food|2026-01-01|80", "warning"},
+		{99, "b|food|2026-01-01|80", "warning"},
+		// only the highest threshold crossed
+		{100, "b|food|2026-01-01|100", "critical"},
+		{130, "b|food|2026-01-01|100", "critical"},
+	}
+	for _, tt := range tests {
+		got := Evaluate([]domain.AlertRule{rule}, Input{Budgets: []domain.BudgetStatus{status(tt.pct)}, Now: evalNow})
+		if tt.key == "" {
+			if len(got) != 0 {
+				t.Errorf("%.1f%%: alerts %v, want none", tt.pct, keys(got))
+			}
+			continue
+		}
+		if len(got) != 1 || got[0].Key != tt.key || got[0].Severity != tt.sev {
+			t.Errorf("%.1f%%: alerts %+v, want %s (%s)", tt.pct, got, tt.key, tt.sev)
+		}
+	}
+
+	got := Evaluate([]domain.AlertRule{rule}, Input{Budgets: []domain.BudgetStatus{status(80)}, Now: evalNow})
+	if want := "320.00 EUR spent of 400.00 (period 2026-01-01 to 2026-01-31, 80.00 left)"; got[0].Message != want {
+		t.Errorf("message = %q, want %q", got[0].Message, want)
+	}
+	yen := status(80)
+	yen.Currency, yen.SpentCents, yen.AvailableCents, yen.RemainingCents = "JPY", 32000, 40000, 8000
+	got = Evaluate([]domain.AlertRule{rule}, Input{Budgets: []domain.BudgetStatus{yen}, Now: evalNow})
+	if want := "32000 JPY spent of 40000 (period 2026-01-01 to 2026-01-31, 8000 left)"; got[0].Message != want {
+		t.Errorf("message = %q, want %q", got[0].Message, want)
+	}
+
+	// custom thresholds and a budget filter
+	rule.Thresholds, rule.BudgetIDs = []float64{50}, []string{"rent"}
+	if got := Evaluate([]domain.AlertRule{rule}, Input{Budgets: []domain.BudgetStatus{status(60)}, Now: evalNow}); len(got) != 0 {
+		t.Errorf("budget filter ignored: %v", keys(got))
+	}
+}
+
+func TestLargeTxAlerts(t *testing.T) {
+	rule := domain.AlertRule{ID: "big", Type: domain.AlertRuleLargeTx, Enabled: true, AmountCents: 50000}
+	batch := []domain.Transaction{
+		{TxUID: "small", AccountID: "main", AmountCents: -49999, Currency: "EUR", BookingDate: day(2)},
+		{TxUID: "sofa", AccountID: "main", AmountCents: -61200, Currency: "EUR", BookingDate: day(3), Merchant: "Möbelhaus"},
+		{TxUID: "salary", AccountID: "main", AmountCents: 300000, Currency: "EUR", BookingDate: day(4)},
+		{TxUID: "savings", AccountID: "main", AmountCents: -100000, Currency: "EUR", BookingDate: day(5), CategoryID: domain.CategoryTransfer},
+	}
+
+	got := Evaluate([]domain.AlertRule{rule}, Input{AccountID: "main", Batch: batch, Now: evalNow})
+	if k := keys(got); len(k) != 2 || k[0] != "big|sofa" || k[1] != "big|salary" {
+		t.Fatalf("alerts = %v, want big|sofa and big|salary", k)
+	}
+	if got[0].Title != "Large transaction: -612.00 EUR" {
+		t.Errorf("title = %q", got[0].Title)
+	}
+
+	if got := Evaluate([]domain.AlertRule{rule}, Input{AccountID: "main", Batch: batch, FirstImport: true, Now: evalNow}); len(got) != 0 {
+		t.Errorf("first import raised %v", keys(got))
+	}
+
+	rule.AccountIDs = []string{"card"}
+	if got := Evaluate([]domain.AlertRule{rule}, Input{AccountID: "main", Batch: batch, Now: evalNow}); len(got) != 0 {
+		t.Errorf("rule for another account raised %v", keys(got))
+	}
+}
+
+func TestNewPayeeAlerts(t *testing.T) {
+	rule := domain.AlertRule{ID: "np", Type: domain.AlertRuleNewPayee, Enabled: true}
+	batch := []domain.Transaction{
+		{TxUID: "1", AccountID: "main", Merchant: "Rewe", AmountCents: -1250, Currency: "EUR", BookingDate: day(2)},
+		{TxUID: "2", AccountID: "main", Payee: " Zahnarzt Dr. Weber ", AmountCents: -8000, Currency: "EUR", BookingDate: day(3)},
+		{TxUID: "3", AccountID: "main", Payee: "zahnarzt dr. weber", AmountCents: -2000, Currency: "EUR", BookingDate: day(4)},
+		{TxUID: "4", AccountID: "main", Payee: "Tagesgeld", CategoryID: domain.CategoryTransfer, AmountCents: -5000, BookingDate: day(5)},
+		{TxUID: "5", AccountID: "main", AmountCents: -100, BookingDate: day(6)},
+	}
+	in := Input{AccountID: "main", Batch: batch, KnownMerchants: map[string]bool{"rewe": true}, Now: evalNow}
+
+	got := Evaluate([]domain.AlertRule{rule}, in)
+	if len(got) != 1 || got[0].Key != "np|main|zahnarzt dr. weber" || got[0].TxUID != "2" {
+		t.Fatalf("alerts = %+v, want one for the dentist", got)
+	}
+	if got[0].Message != "-80.00 EUR on 2026-01-03" {
+		t.Errorf("message = %q", got[0].Message)
+	}
+
+	in.FirstImport = true
+	if got := Evaluate([]domain.AlertRule{rule}, in); len(got) != 0 {
+		t.Errorf("first import raised %v", keys(got))
+	}
+}
+
+func TestLowBalanceAlerts(t *testing.T) {
+	rule := domain.AlertRule{ID: "low", Type: domain.AlertRuleLowBalance, Enabled: true, AmountCents: 10000}
+	tests := []struct {
+		name  string
+		batch []domain.Transaction
+		key   string
+	}{
+		{"no balances", []domain.Transaction{{AccountID: "main", BookingDate: day(2)}}, ""},
+		{"latest above", []domain.Transaction{
+			{AccountID: "main", BookingDate: day(2), BalanceCents: balance(5000)},
+			{AccountID: "main", BookingDate: day(3), BalanceCents: balance(20000)},
+		}, ""},
+		{"latest below, oldest first", []domain.Transaction{
+			{AccountID: "main", BookingDate: day(2), BalanceCents: balance(20000)},
+			{AccountID: "main", BookingDate: day(3), BalanceCents: balance(15000)},
+			{AccountID: "main", BookingDate: day(3), BalanceCents: balance(9000)},
+		}, "low|main|2026-01-03"},
+		{"latest below, newest first", []domain.Transaction{
+			{AccountID: "main", BookingDate: day(3), BalanceCents: balance(9000)},
+			{AccountID: "main", BookingDate: day(3), BalanceCents: balance(15000)},
+			{AccountID: "main", BookingDate: day(2), BalanceCents: balance(20000)},
+		}, "low|main|2026-01-03"},
+		{"one day only: the first row counts as newest", []domain.Transaction{
+			{AccountID: "main", BookingDate: day(3), BalanceCents: balance(15000)},
+			{AccountID: "main", BookingDate: day(3), BalanceCents: balance(9000)},
+		}, ""},
+	}
+	for _, tt := range tests {
+		got := Evaluate([]domain.AlertRule{rule}, Input{AccountID: "main", Batch: tt.batch, Now: evalNow})
+		if tt.key == "" {
+			if len(got) != 0 {
+				t.Errorf("%s: alerts %v, want none", tt.name, keys(got))
+			}
+			continue
+		}
+		if len(got) != 1 || got[0].Key != tt.key || got[0].Severity != "critical" {
+			t.Errorf("%s: alerts %+v, want %s", tt.name, got, tt.key)
+		}
+	}
+}
+
+func TestEvaluateSkipsDisabledRules(t *testing.T) {
+	rule := domain.AlertRule{ID: "big", Type: domain.AlertRuleLargeTx, AmountCents: 1}
+	in := Input{AccountID: "main", Batch: []domain.Transaction{{TxUID: "x", AmountCents: -500}}, Now: evalNow}
+	if got := Evaluate([]domain.AlertRule{rule}, in); len(got) != 0 {
+		t.Errorf("disabled rule raised %v", keys(got))
+	}
+}
@@ -0,0 +1,225 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"bankdash/backend/internal/domain"
+)
+
+// Notifier delivers an alert to one channel.
+type Notifier interface {
+	Send(ctx context.Context, a domain.Alert) error
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// smtpTimeout bounds one mail, from dial to QUIT.
+const smtpTimeout = 10 * time.Second
+
+func NewNotifier(ch domain.AlertChannel) (Notifier, error) {
+	switch ch.Type {
+	case domain.AlertChannelWebhook:
+		if ch.URL == "" {
+			return nil, fmt.Errorf("webhook channel %s: url is required", ch.ID)
+		}
+		return webhook{ch}, nil
+	case domain.AlertChannelNtfy:
+		if ch.URL == "" {
+			return nil, fmt.Errorf("ntfy channel %s: url is required", ch.ID)
+		}
+		return ntfy{ch}, nil
+	case domain.AlertChannelSMTP:
+		if ch.SMTP.Host == "" || ch.SMTP.From == "" || len(ch.SMTP.To) == 0 {
+			return nil, fmt.Errorf("smtp channel %s: host, from and to are required", ch.ID)
+		}
+		return mailer{ch.SMTP}, nil
+	default:
+		return nil, fmt.Errorf("unknown channel type: %q", ch.Type)
+	}
+}
+
+// webhook POSTs the alert as JSON.
+type webhook struct{ ch domain.AlertChannel }
+
+func (n webhook) Send(ctx context.Context, a domain.Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.ch.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("content-type", "application/json")
+	for k, v := range n.ch.Headers {
+		req.Header.Set(k, v)
+	}
+	return do(req)
+}
+
+// ntfy publishes a plain-text message to a topic URL (ntfy.sh or compatible).
+type ntfy struct{ ch domain.AlertChannel }
+
+var ntfyPriority = map[string]string{"info": "default", "warning": "high", "critical": "urgent"}
+
+func (n ntfy) Send(ctx context.Context, a domain.Alert) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.ch.URL, strings.NewReader(a.Message))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", mime.QEncoding.Encode("utf-8", a.Title))
+	req.Header.Set("Tags", "bankdash,"+a.Type)
+	if p := ntfyPriority[a.Severity]; p != "" {
+		req.Header.Set("Priority", p)
+	}
+	if n.ch.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.ch.Token)
+	}
+	for k, v := range n.ch.Headers {
+		req.Header.Set(k, v)
+	}
+	return do(req)
+}
+
+func do(req *http.Request) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s %s", req.Method, req.URL.Host, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// mailer sends a plain-text mail. STARTTLS is used when the server offers
+// it; auth only when a username is set (net/smtp refuses PLAIN auth over an
+// unencrypted connection to anything but localhost).
+type mailer struct{ cfg domain.SMTPSettings }
+
+func (n mailer) Send(ctx context.Context, a domain.Alert) error {
+	port := n.cfg.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(port))
+
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "[bankdash] "+headerSafe(a.Title)))
+	fmt.Fprintf(&msg, "Date: %s\r\n", a.At.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(a.Message + "\r\n")
+
+	// smtp.SendMail dials without a timeout; this is SendMail with one
+	conn, err := (&net.Dialer{Timeout: smtpTimeout}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(smtpTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server does not support AUTH")
+		}
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.cfg.From); err != nil {
+		return err
+	}
+	for _, to := range n.cfg.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg.Bytes()); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func headerSafe(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+// Dispatch sends every alert to the channels of its rule (all channels when
+// the rule names none). It returns the alerts that reached at least one
+// channel, plus the delivery errors joined.
+func Dispatch(ctx context.Context, rules []domain.AlertRule, channels []domain.AlertChannel, alerts []domain.Alert) ([]domain.Alert, error) {
+	ruleChannels := map[string][]string{}
+	for _, r := range rules {
+		ruleChannels[r.ID] = r.Channels
+	}
+
+	var sent []domain.Alert
+	var errs []error
+	for _, a := range alerts {
+		ok := false
+		for _, ch := range channels {
+			if ids := ruleChannels[a.RuleID]; len(ids) > 0 && !contains(ids, ch.ID) {
+				continue
+			}
+			n, err := NewNotifier(ch)
+			if err == nil {
+				err = n.Send(ctx, a)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("channel %s: %w", ch.ID, err))
+				continue
+			}
+			ok = true
+		}
+		if ok {
+			sent = append(sent, a)
+		}
+	}
+	return sent, errors.Join(errs...)
+}
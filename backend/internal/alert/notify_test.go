@@ -0,0 +1,272 @@
+package alert
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bankdash/backend/internal/domain"
+)
+
+var testAlert = domain.Alert{
+	Key:      "big|abc",
+	RuleID:   "big",
+	Type:     domain.AlertRuleLargeTx,
+	Severity: "warning",
+	Title:    "Large transaction: -612.00 EUR",
+	Message:  "Möbelhaus on 2026-01-02: Sofa",
+	At:       time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC),
+}
+
+func TestWebhook(t *testing.T) {
+	var got domain.Alert
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("X-Token")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	n, err := NewNotifier(domain.AlertChannel{ID: "hook", Type: domain.AlertChannelWebhook, URL: srv.URL,
+		Headers: map[string]string{"X-Token": "secret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Send(context.Background(), testAlert); err != nil {
+		t.Fatal(err)
+	}
+	if got.Key != testAlert.Key || got.Message != testAlert.Message || auth != "secret" {
+		t.Fatalf("webhook got %+v with X-Token %q", got, auth)
+	}
+}
+
+func TestWebhookErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	n, _ := NewNotifier(domain.AlertChannel{ID: "hook", Type: domain.AlertChannelWebhook, URL: srv.URL})
+	err := n.Send(context.Background(), testAlert)
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("Send() = %v, want the 502", err)
+	}
+}
+
+func TestNtfy(t *testing.T) {
+	var header http.Header
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer srv.Close()
+
+	n, err := NewNotifier(domain.AlertChannel{ID: "push", Type: domain.AlertChannelNtfy, URL: srv.URL + "/bankdash", Token: "tk"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Send(context.Background(), testAlert); err != nil {
+		t.Fatal(err)
+	}
+	if body != testAlert.Message {
+		t.Errorf("body = %q, want the message", body)
+	}
+	if got := header.Get("Priority"); got != "high" {
+		t.Errorf("Priority = %q, want high", got)
+	}
+	if got := header.Get("Authorization"); got != "Bearer tk" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := header.Get("Tags"); got != "bankdash,large_tx" {
+		t.Errorf("Tags = %q", got)
+	}
+	if got := header.Get("Title"); got != testAlert.Title {
+		t.Errorf("Title = %q, want %q", got, testAlert.Title)
+	}
+}
+
+// smtpStandIn accepts one mail and hands its envelope and data to got.
+func smtpStandIn(t *testing.T, got chan<- []string) (host string, port int) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = io.WriteString(conn, s+"\r\n") }
+		var lines []string
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch cmd {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "MAIL", "RCPT":
+				lines = append(lines, line)
+				reply("250 ok")
+			case "DATA":
+				reply("354 go ahead")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					lines = append(lines, strings.TrimRight(l, "\r\n"))
+				}
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				got <- lines
+				return
+			default:
+				reply("502 unknown")
+			}
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func TestMailer(t *testing.T) {
+	got := make(chan []string, 1)
+	host, port := smtpStandIn(t, got)
+
+	n, err := NewNotifier(domain.AlertChannel{ID: "mail", Type: domain.AlertChannelSMTP, SMTP: domain.SMTPSettings{
+		Host: host, Port: port, From: "bankdash@example.org", To: []string{"me@example.org", "you@example.org"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Send(context.Background(), testAlert); err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	select {
+	case lines = <-got:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stand-in server got no mail")
+	}
+	mail := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"MAIL FROM:<bankdash@example.org>",
+		"RCPT TO:<me@example.org>",
+		"RCPT TO:<you@example.org>",
+		"To: me@example.org, you@example.org",
+		"Subject: [bankdash] Large transaction: -612.00 EUR",
+		"Content-Type: text/plain; charset=utf-8",
+		testAlert.Message,
+	} {
+		if !strings.Contains(mail, want) {
+			t.Errorf("mail lacks %q:\n%s", want, mail)
+		}
+	}
+}
+
+func TestMailerTimeout(t *testing.T) {
+	// accepts and never greets, like a black-holed relay
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(5 * time.Second)
+		}
+	}()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	n, _ := NewNotifier(domain.AlertChannel{ID: "mail", Type: domain.AlertChannelSMTP, SMTP: domain.SMTPSettings{
+		Host: "127.0.0.1", Port: port, From: "a@example.org", To: []string{"b@example.org"},
+	}})
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := n.Send(ctx, testAlert); err == nil {
+		t.Fatal("Send() = nil, want a timeout")
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Fatalf("Send() took %s despite a 200ms deadline", d)
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	hits := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits[r.URL.Path]++
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	channels := []domain.AlertChannel{
+		{ID: "a", Type: domain.AlertChannelWebhook, URL: srv.URL + "/a"},
+		{ID: "b", Type: domain.AlertChannelWebhook, URL: srv.URL + "/b"},
+		{ID: "down", Type: domain.AlertChannelWebhook, URL: srv.URL + "/down"},
+	}
+	rules := []domain.AlertRule{
+		{ID: "only-a", Channels: []string{"a"}},
+		{ID: "only-down", Channels: []string{"down"}},
+	}
+	alerts := []domain.Alert{
+		{Key: "1", RuleID: "only-a"},
+		{Key: "2", RuleID: "only-down"},
+		{Key: "3", RuleID: "all"},
+	}
+	sent, err := Dispatch(context.Background(), rules, channels, alerts)
+	if err == nil {
+		t.Error("Dispatch() error = nil, want the failing channel")
+	}
+	var keys []string
+	for _, a := range sent {
+		keys = append(keys, a.Key)
+	}
+	if strings.Join(keys, ",") != "1,3" {
+		t.Errorf("sent = %v, want 1 and 3", keys)
+	}
+	if hits["/a"] != 2 || hits["/b"] != 1 || hits["/down"] != 2 {
+		t.Errorf("hits = %v", hits)
+	}
+}
+
+func TestNewNotifierRequiresSettings(t *testing.T) {
+	for _, ch := range []domain.AlertChannel{
+		{ID: "x", Type: domain.AlertChannelWebhook},
+		{ID: "x", Type: domain.AlertChannelNtfy},
+		{ID: "x", Type: domain.AlertChannelSMTP, SMTP: domain.SMTPSettings{Host: "mail", From: "a@b"}},
+		{ID: "x", Type: "pager"},
+	} {
+		if _, err := NewNotifier(ch); err == nil {
+			t.Errorf("NewNotifier(%+v) = nil error", ch)
+		}
+	}
+}
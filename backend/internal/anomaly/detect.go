@@ -0,0 +1,231 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/util"
+)
+
+const (
+	// unusual amount: robust z-score over the merchant's earlier bookings
+	minMerchantHistory = 5
+	maxRobustZ         = 3.5
+	minAmountDeviation = 1000 // cents; ignore a few euros off a cheap merchant
+
+	// duplicate charge: card purchases with exact purchase times
+	duplicateWindow = 10 * time.Minute
+
+	// category spike: month total vs. median of the previous months
+	spikeMonths       = 6
+	minSpikeHistory   = 3
+	spikeFactor       = 1.5
+	minSpikeDeviation = 5000 // cents
+)
+
+// Detect finds anomalies in txs at or after from; everything before from
+// only serves as history. txs may come in any order and from several accounts.
+func Detect(txs []domain.Transaction, from time.Time) []domain.Anomaly {
+	var use []domain.Transaction
+	for _, tx := range txs {
+		if tx.CategoryID == domain.CategoryTransfer || tx.AmountCents == 0 {
+			continue
+		}
+		use = append(use, tx)
+	}
+	sort.SliceStable(use, func(i, j int) bool { return when(use[i]).Before(when(use[j])) })
+
+	var out []domain.Anomaly
+	out = append(out, unusualAmounts(use, from)...)
+	out = append(out, duplicates(use, from)...)
+	out = append(out, categorySpikes(use, from)...)
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].Time.Equal(out[j].Time) {
+			return out[i].Time.Before(out[j].Time)
+		}
+		return out[i].AccountID+out[i].CategoryID+out[i].TxUID < out[j].AccountID+out[j].CategoryID+out[j].TxUID
+	})
+	return out
+}
+
+// Flag copies transaction-level findings into the Flags of matching txs.
+func Flag(txs []domain.Transaction, found []domain.Anomaly) {
+	byUID := map[string][]string{}
+	for _, a := range found {
+		if a.TxUID != "" {
+			byUID[a.TxUID] = append(byUID[a.TxUID], a.Kind)
+		}
+	}
+	for i := range txs {
+		if kinds := byUID[txs[i].TxUID]; len(kinds) > 0 {
+			txs[i].Flags = kinds
+		}
+	}
+}
+
+func unusualAmounts(txs []domain.Transaction, from time.Time) []domain.Anomaly {
+	history := map[string][]float64{}
+	var out []domain.Anomaly
+	for _, tx := range txs {
+		k := tx.AccountID + "|" + merchantOf(tx) + "|" + tx.Direction
+		amt := math.Abs(float64(tx.AmountCents))
+		prev := history[k]
+		history[k] = append(prev, amt)
+		if len(prev) < minMerchantHistory || when(tx).Before(from) {
+			continue
+		}
+		med := median(prev)
+		dev := make([]float64, len(prev))
+		for i, v := range prev {
+			dev[i] = math.Abs(v - med)
+		}
+		// 1.4826 scales the MAD to a standard deviation for normal data;
+		// a 1% floor keeps perfectly constant histories from dividing by zero
+		mad := math.Max(median(dev)*1.4826, med*0.01)
+		z := math.Abs(amt-med) / mad
+		if z < maxRobustZ || math.Abs(amt-med) < minAmountDeviation {
+			continue
+		}
+		out = append(out, domain.Anomaly{
+			Kind:          domain.AnomalyUnusualAmount,
+			AccountID:     tx.AccountID,
+			CategoryID:    tx.CategoryID,
+			Merchant:      merchantOf(tx),
+			TxUID:         tx.TxUID,
+			Time:          when(tx),
+			AmountCents:   tx.AmountCents,
+			ExpectedCents: signed(int64(med), tx.AmountCents),
+			Score:         round2(z),
+			Message: fmt.Sprintf("%s: %s instead of the usual %s",
+				merchantOf(tx), util.FormatMinor(tx.AmountCents, tx.Currency, "."), util.FormatMinor(signed(int64(med), tx.AmountCents), tx.Currency, ".")),
+		})
+	}
+	return out
+}
+
+func duplicates(txs []domain.Transaction, from time.Time) []domain.Anomaly {
+	var out []domain.Anomaly
+	for i, tx := range txs {
+		if tx.PurchaseDate == nil || when(tx).Before(from) {
+			continue
+		}
+		for j := i - 1; j >= 0; j-- {
+			prev := txs[j]
+			if prev.PurchaseDate == nil {
+				continue
+			}
+			gap := tx.PurchaseDate.Sub(*prev.PurchaseDate)
+			if gap > duplicateWindow {
+				break
+			}
+			if prev.AccountID != tx.AccountID || prev.AmountCents != tx.AmountCents || merchantOf(prev) != merchantOf(tx) {
+				continue
+			}
+			out = append(out, domain.Anomaly{
+				Kind:        domain.AnomalyDuplicateCharge,
+				AccountID:   tx.AccountID,
+				CategoryID:  tx.CategoryID,
+				Merchant:    merchantOf(tx),
+				TxUID:       tx.TxUID,
+				Time:        when(tx),
+				AmountCents: tx.AmountCents,
+				Score:       round2(gap.Minutes()),
+				Message: fmt.Sprintf("%s charged %s twice within %s",
+					merchantOf(tx), util.FormatMinor(tx.AmountCents, tx.Currency, "."), gap.Round(time.Second)),
+			})
+			break
+		}
+	}
+	return out
+}
+
+func categorySpikes(txs []domain.Transaction, from time.Time) []domain.Anomaly {
+	type key struct{ account, category string }
+	monthly := map[key]map[time.Time]int64{}
+	for _, tx := range txs {
+		if tx.AmountCents >= 0 {
+			continue
+		}
+		k := key{tx.AccountID, tx.CategoryID}
+		if monthly[k] == nil {
+			monthly[k] = map[time.Time]int64{}
+		}
+		monthly[k][monthOf(tx.BookingDate)] -= tx.AmountCents
+	}
+
+	var out []domain.Anomaly
+	for k, months := range monthly {
+		for m, total := range months {
+			if m.Before(monthOf(from)) {
+				continue
+			}
+			var prev []float64
+			for i := 1; i <= spikeMonths; i++ {
+				if v, ok := months[m.AddDate(0, -i, 0)]; ok {
+					prev = append(prev, float64(v))
+				}
+			}
+			if len(prev) < minSpikeHistory {
+				continue
+			}
+			med := median(prev)
+			if float64(total) < med*spikeFactor || float64(total)-med < minSpikeDeviation {
+				continue
+			}
+			out = append(out, domain.Anomaly{
+				Kind:          domain.AnomalyCategorySpike,
+				AccountID:     k.account,
+				CategoryID:    k.category,
+				Time:          m,
+				AmountCents:   -total,
+				ExpectedCents: -int64(med),
+				Score:         round2(float64(total) / med),
+				Message: fmt.Sprintf("%s spending in %s: %s vs. median %s",
+					k.category, m.Format("2006-01"), util.FormatMinor(total, "", "."), util.FormatMinor(int64(med), "", ".")),
+			})
+		}
+	}
+	return out
+}
+
+// when is the best known time of a booking.
+func when(tx domain.Transaction) time.Time {
+	if tx.PurchaseDate != nil {
+		return *tx.PurchaseDate
+	}
+	return tx.BookingDate
+}
+
+func merchantOf(tx domain.Transaction) string {
+	if tx.Merchant != "" {
+		return tx.Merchant
+	}
+	return strings.Join(strings.Fields(tx.Payee), " ")
+}
+
+func monthOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func median(v []float64) float64 {
+	s := append([]float64(nil), v...)
+	sort.Float64s(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
+
+func signed(v, like int64) int64 {
+	if like < 0 {
+		return -v
+	}
+	return v
+}
+
+func round2(v float64) float64 { return math.Round(v*100) / 100 }
@@ -0,0 +1,151 @@
+package budget
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bankdash/backend/internal/domain"
+)
+
+// rollover looks back at most this many periods
+const maxRolloverPeriods = 12
+
+func Validate(b domain.Budget) error {
+	if strings.TrimSpace(b.ID) == "" {
+		return fmt.Errorf("budget id is required")
+	}
+	if b.LimitCents <= 0 {
+		return fmt.Errorf("budget limitCents must be positive")
+	}
+	if b.StartDay < 0 || b.StartDay > 28 {
+		return fmt.Errorf("budget startDay must be 1..28")
+	}
+	if b.Currency != "" && !isCurrencyCode(b.Currency) {
+		return fmt.Errorf("budget currency must be an ISO 4217 code like EUR: %q", b.Currency)
+	}
+	switch b.Rollover {
+	case "", domain.RolloverNone, domain.RolloverSurplus, domain.RolloverFull:
+	default:
+		return fmt.Errorf("unknown rollover: %q", b.Rollover)
+	}
+	return nil
+}
+
+// Currency is the currency b counts, EUR for budgets saved without one
+// (summing EUR and JPY minor units would be meaningless).
+func Currency(b domain.Budget) string {
+	if b.Currency == "" {
+		return "EUR"
+	}
+	return b.Currency
+}
+
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, c := range s {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Period returns the [start, end) period of b that contains at, on calendar
+// dates (UTC midnight, matching booking dates read back from Influx).
+func Period(b domain.Budget, at time.Time) (time.Time, time.Time) {
+	day := b.StartDay
+	if day < 1 {
+		day = 1
+	}
+	y, m, d := at.Date()
+	start := time.Date(y, m, day, 0, 0, 0, 0, time.UTC)
+	if d < day {
+		start = start.AddDate(0, -1, 0)
+	}
+	return start, start.AddDate(0, 1, 0)
+}
+
+// LookbackStart is the earliest date Status needs transactions from.
+func LookbackStart(b domain.Budget, at time.Time) time.Time {
+	start, _ := Period(b, at)
+	if b.Rollover == "" || b.Rollover == domain.RolloverNone {
+		return start
+	}
+	return start.AddDate(0, -maxRolloverPeriods, 0)
+}
+
+// Status computes spent vs. limit for the period containing at.
+func Status(b domain.Budget, txs []domain.Transaction, at time.Time) domain.BudgetStatus {
+	start, end := Period(b, at)
+
+	var rollover int64
+	if b.Rollover == domain.RolloverSurplus || b.Rollover == domain.RolloverFull {
+		first := start.AddDate(0, -maxRolloverPeriods, 0)
+		if created, _ := Period(b, b.CreatedAt); created.After(first) {
+			first = created
+		}
+		for p := first; p.Before(start); p = p.AddDate(0, 1, 0) {
+			left := b.LimitCents + rollover - spent(b, txs, p, p.AddDate(0, 1, 0))
+			if left < 0 && b.Rollover == domain.RolloverSurplus {
+				left = 0
+			}
+			rollover = left
+		}
+	}
+
+	st := domain.BudgetStatus{
+		BudgetID:      b.ID,
+		Name:          b.Name,
+		CategoryID:    b.CategoryID,
+		Currency:      Currency(b),
+		PeriodStart:   start,
+		PeriodEnd:     end,
+		LimitCents:    b.LimitCents,
+		RolloverCents: rollover,
+		SpentCents:    spent(b, txs, start, end),
+	}
+	st.AvailableCents = st.LimitCents + st.RolloverCents
+	st.RemainingCents = st.AvailableCents - st.SpentCents
+	if st.AvailableCents > 0 {
+		st.PercentUsed = float64(st.SpentCents) * 100 / float64(st.AvailableCents)
+	} else if st.SpentCents > 0 {
+		st.PercentUsed = 100
+	}
+	return st
+}
+
+// spent sums outgoing amounts (as a positive number) matching b in [from, to).
+func spent(b domain.Budget, txs []domain.Transaction, from, to time.Time) int64 {
+	var sum int64
+	for _, tx := range txs {
+		if !matches(b, tx) || tx.BookingDate.Before(from) || !tx.BookingDate.Before(to) {
+			continue
+		}
+		sum -= tx.AmountCents
+	}
+	return sum
+}
+
+func matches(b domain.Budget, tx domain.Transaction) bool {
+	if tx.AmountCents >= 0 || tx.CategoryID == domain.CategoryTransfer {
+		return false
+	}
+	if b.CategoryID != "" && tx.CategoryID != b.CategoryID {
+		return false
+	}
+	if tx.Currency != Currency(b) {
+		return false
+	}
+	if len(b.AccountIDs) == 0 {
+		return true
+	}
+	for _, id := range b.AccountIDs {
+		if id == tx.AccountID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"bankdash/backend/internal/domain"
+)
+
+func TestStatusCountsOneCurrency(t *testing.T) {
+	at := time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC)
+	txs := []domain.Transaction{
+		{AccountID: "main", AmountCents: -2500, Currency: "EUR", BookingDate: at},
+		{AccountID: "card", AmountCents: -1500, Currency: "JPY", BookingDate: at},
+		{AccountID: "card", AmountCents: -900, Currency: "USD", BookingDate: at},
+	}
+	tests := []struct {
+		currency string
+		want     int64
+	}{
+		{"", 2500}, // saved before budgets had a currency
+		{"EUR", 2500},
+		{"JPY", 1500},
+	}
+	for _, tt := range tests {
+		b := domain.Budget{ID: "all", LimitCents: 10000, StartDay: 1, Currency: tt.currency}
+		st := Status(b, txs, at)
+		if st.SpentCents != tt.want || st.Currency != Currency(b) {
+			t.Errorf("currency %q: spent %d %s, want %d", tt.currency, st.SpentCents, st.Currency, tt.want)
+		}
+	}
+}
+
+func TestValidateCurrency(t *testing.T) {
+	for cur, ok := range map[string]bool{"": true, "EUR": true, "JPY": true, "eur": false, "€": false, "EURO": false} {
+		err := Validate(domain.Budget{ID: "b", LimitCents: 1, StartDay: 1, Currency: cur})
+		if (err == nil) != ok {
+			t.Errorf("Validate(currency %q) = %v", cur, err)
+		}
+	}
+}
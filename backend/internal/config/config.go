@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -16,6 +18,14 @@ type Config struct {
 	MetaDBPath    string
 	DefaultTenant string
 	TemplateDir   string
+
+	// IANA zone for imports when neither the account nor the template sets one
+	DefaultTimezone string
+
+	// max booking date distance between the two legs of an internal transfer
+	TransferWindowDays int
+	// how long a booking may stay pending before its booked version arrives
+	PendingWindowDays int
 }
 
 func Load() (Config, error) {
@@ -28,6 +38,19 @@ func Load() (Config, error) {
 		MetaDBPath:    getenv("META_DB_PATH", "/data/meta.db"),
 		DefaultTenant: getenv("DEFAULT_TENANT_ID", "default"),
 		TemplateDir:   getenv("TEMPLATE_DIR", "./config/templates"),
+
+		DefaultTimezone: getenv("DEFAULT_TIMEZONE", "Europe/Berlin"),
+	}
+	if _, err := time.LoadLocation(cfg.DefaultTimezone); err != nil {
+		return cfg, fmt.Errorf("invalid DEFAULT_TIMEZONE %q: %w", cfg.DefaultTimezone, err)
+	}
+
+	var err error
+	if cfg.TransferWindowDays, err = getenvInt("TRANSFER_WINDOW_DAYS", 3); err != nil {
+		return cfg, err
+	}
+	if cfg.PendingWindowDays, err = getenvInt("PENDING_WINDOW_DAYS", 10); err != nil {
+		return cfg, err
 	}
 
 	if cfg.InfluxToken == "" || cfg.InfluxOrg == "" || cfg.InfluxBucket == "" {
@@ -43,3 +66,15 @@ func getenv(key, def string) string {
 	}
 	return v
 }
+
+func getenvInt(key string, def int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,21 @@
+package domain
+
+// Account is one of the tenant's own bank accounts. Registering accounts lets
+// the importer recognize money moving between them.
+type Account struct {
+	ID     string `json:"id"` // same value as account_id on imports
+	Name   string `json:"name"`
+	BankID string `json:"bankId"`
+	IBAN   string `json:"iban"`
+
+	// names the account shows up under as counterparty on the other side,
+	// e.g. the holder's name on a Girokonto -> Extra-Konto transfer
+	HolderNames []string `json:"holderNames"`
+
+	// journal account for Beancount/hledger/ledger exports, e.g.
+	// "Assets:Bank:ING:Giro"; derived from Name when empty
+	LedgerAccount string `json:"ledgerAccount,omitempty"`
+
+	// IANA zone for imports into this account; overrides the template's
+	Timezone string `json:"timezone,omitempty"`
+}
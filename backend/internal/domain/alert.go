@@ -0,0 +1,63 @@
+package domain
+
+import "time"
+
+const (
+	AlertRuleBudget     = "budget"      // budget usage crossed one of Thresholds (percent)
+	AlertRuleLargeTx    = "large_tx"    // single booking with |amount| >= AmountCents
+	AlertRuleNewPayee   = "new_payee"   // merchant not seen on the account before
+	AlertRuleLowBalance = "low_balance" // latest balance < AmountCents
+
+	AlertChannelWebhook = "webhook"
+	AlertChannelSMTP    = "smtp"
+	AlertChannelNtfy    = "ntfy"
+)
+
+// AlertRule is evaluated after every import.
+type AlertRule struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+
+	Thresholds  []float64 `json:"thresholds"`  // budget: percents, default [80, 100]
+	AmountCents int64     `json:"amountCents"` // large_tx / low_balance
+	BudgetIDs   []string  `json:"budgetIds"`   // budget: empty = all
+	AccountIDs  []string  `json:"accountIds"`  // empty = all
+	Channels    []string  `json:"channels"`    // channel ids, empty = all
+}
+
+// AlertChannel is a notification target.
+type AlertChannel struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+
+	// webhook: JSON POST target; ntfy: full topic URL, e.g. https://ntfy.sh/bankdash
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Token   string            `json:"token"` // ntfy access token (Bearer)
+
+	SMTP SMTPSettings `json:"smtp"`
+}
+
+type SMTPSettings struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username"` // empty = no auth
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// Alert is one notification. Key identifies it for de-duplication, so a
+// re-import does not notify twice.
+type Alert struct {
+	Key       string    `json:"key"`
+	RuleID    string    `json:"ruleId"`
+	Type      string    `json:"type"`
+	Severity  string    `json:"severity"` // "info"|"warning"|"critical"
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	AccountID string    `json:"accountId,omitempty"`
+	TxUID     string    `json:"txUid,omitempty"`
+	At        time.Time `json:"at"`
+}
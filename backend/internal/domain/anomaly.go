@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+const (
+	AnomalyUnusualAmount   = "unusual_amount"   // far off the merchant's usual amount
+	AnomalyDuplicateCharge = "duplicate_charge" // same merchant and amount within minutes
+	AnomalyCategorySpike   = "category_spike"   // month total far above the rolling median
+)
+
+// Anomaly is one finding. Transaction-level findings carry TxUID and also
+// end up in that transaction's Flags; category spikes cover a whole month.
+type Anomaly struct {
+	Kind       string    `json:"kind"`
+	AccountID  string    `json:"accountId"`
+	CategoryID string    `json:"categoryId,omitempty"`
+	Merchant   string    `json:"merchant,omitempty"`
+	TxUID      string    `json:"txUid,omitempty"`
+	Time       time.Time `json:"time"` // purchase/booking time, or month start for spikes
+
+	AmountCents   int64   `json:"amountCents"`
+	ExpectedCents int64   `json:"expectedCents"` // the baseline it deviates from
+	Score         float64 `json:"score"`
+	Message       string  `json:"message"`
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+const (
+	RolloverNone    = "none"    // every period starts at Limit
+	RolloverSurplus = "surplus" // unspent money carries over, overspending doesn't
+	RolloverFull    = "full"    // both unspent money and overspending carry over
+)
+
+// Budget caps outgoing spending per period. Periods are monthly and start on
+// StartDay (1 = calendar month, 25 = payday on the 25th).
+type Budget struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	CategoryID string   `json:"categoryId"` // empty = all spending except transfers
+	AccountIDs []string `json:"accountIds"` // empty = all accounts
+
+	LimitCents int64  `json:"limitCents"`
+	Currency   string `json:"currency"` // ISO 4217, EUR if empty; bookings in other currencies don't count
+	StartDay   int    `json:"startDay"`
+	Rollover   string `json:"rollover"`
+
+	// rollover never reaches back before the period this falls into
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type BudgetStatus struct {
+	BudgetID   string `json:"budgetId"`
+	Name       string `json:"name"`
+	CategoryID string `json:"categoryId"`
+	Currency   string `json:"currency"` // of all amounts below
+
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"` // exclusive
+
+	LimitCents     int64   `json:"limitCents"`
+	RolloverCents  int64   `json:"rolloverCents"`
+	AvailableCents int64   `json:"availableCents"` // limit + rollover
+	SpentCents     int64   `json:"spentCents"`
+	RemainingCents int64   `json:"remainingCents"`
+	PercentUsed    float64 `json:"percentUsed"`
+}
@@ -0,0 +1,38 @@
+package domain
+
+const (
+	SKR03 = "SKR03"
+	SKR04 = "SKR04"
+
+	DatevDateBooking  = "booking"
+	DatevDateValue    = "value"
+	DatevDatePurchase = "purchase"
+)
+
+// DatevSettings configure the DATEV Buchungsstapel export. Every booking is
+// posted between the bank's Sachkonto and the Gegenkonto of its category.
+type DatevSettings struct {
+	ConsultantNumber int    `json:"consultantNumber"` // Beraternummer
+	ClientNumber     int    `json:"clientNumber"`     // Mandantennummer
+	ChartOfAccounts  string `json:"chartOfAccounts"`  // SKR03 | SKR04
+	AccountLength    int    `json:"accountLength"`    // Sachkontenlänge, default 4
+	FiscalYearStart  int    `json:"fiscalYearStart"`  // month 1..12, default 1
+
+	// account_id -> Sachkonto of that bank account; default 1200 (SKR03) / 1800 (SKR04)
+	BankAccounts map[string]string `json:"bankAccounts"`
+	// category_id -> Gegenkonto and BU-Schlüssel
+	Categories map[string]DatevMapping `json:"categories"`
+	// Gegenkonto for unmapped categories; default 1590 (SKR03) / 1370 (SKR04),
+	// the Durchlaufende Posten the advisor re-books
+	DefaultAccount string `json:"defaultAccount"`
+
+	DocumentDate string `json:"documentDate"` // which date becomes Belegdatum: booking | value | purchase
+	// Buchungstext with {payee}, {merchant}, {memo}, {reference}, {category};
+	// cut to DATEV's 60 characters
+	TextTemplate string `json:"textTemplate"`
+}
+
+type DatevMapping struct {
+	Account string `json:"account"`
+	TaxKey  string `json:"taxKey,omitempty"` // BU-Schlüssel, e.g. "9" for 19% Vorsteuer in SKR03
+}
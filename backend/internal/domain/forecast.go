@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// Forecast projects an account's daily balance.
+type Forecast struct {
+	AccountID    string    `json:"accountId"`
+	Currency     string    `json:"currency"`
+	From         time.Time `json:"from"`
+	Days         int       `json:"days"`
+	StartBalance int64     `json:"startBalanceCents"`
+	BalanceKnown bool      `json:"balanceKnown"` // false: no balance column in the imports, projection starts at 0
+
+	MinBalanceCents int64     `json:"minBalanceCents"`
+	MinBalanceDate  time.Time `json:"minBalanceDate"`
+
+	Points []ForecastPoint    `json:"points"`
+	Events []ForecastExpected `json:"events"`
+}
+
+type ForecastPoint struct {
+	Date           time.Time `json:"date"`
+	BalanceCents   int64     `json:"balanceCents"`
+	RecurringCents int64     `json:"recurringCents"` // expected recurring bookings that day
+	BaselineCents  int64     `json:"baselineCents"`  // average non-recurring flow per day
+}
+
+// ForecastExpected is one projected booking of a recurring series.
+type ForecastExpected struct {
+	Date        time.Time `json:"date"`
+	Merchant    string    `json:"merchant"`
+	AmountCents int64     `json:"amountCents"`
+	Interval    string    `json:"interval"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// ImportBatch records one file import, including the exact template
+// revision it was parsed with.
+type ImportBatch struct {
+	ID               string    `json:"id"`
+	TemplateID       string    `json:"templateId"`
+	TemplateRevision int       `json:"templateRevision"`
+	AccountID        string    `json:"accountId"`
+	BankID           string    `json:"bankId"`
+	FileName         string    `json:"fileName"`
+	Imported         int       `json:"imported"`
+	ReplacedPending  int       `json:"replacedPending"` // stored pending bookings replaced by booked ones
+	ImportedAt       time.Time `json:"importedAt"`
+}
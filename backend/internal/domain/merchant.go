@@ -0,0 +1,8 @@
+package domain
+
+// MerchantAlias maps a cleaned payee (prefix match, case-insensitive) to a
+// display merchant name, e.g. "ALDI" -> "Aldi".
+type MerchantAlias struct {
+	Match    string `json:"match"`
+	Merchant string `json:"merchant"`
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// RecurringSeries is a detected subscription / standing payment.
+type RecurringSeries struct {
+	AccountID string `json:"accountId"`
+	Merchant  string `json:"merchant"`
+	Currency  string `json:"currency"`
+	Direction string `json:"direction"`
+	Interval  string `json:"interval"` // "weekly"|"monthly"|"quarterly"|"yearly"
+
+	Count              int       `json:"count"`
+	AverageAmountCents int64     `json:"averageAmountCents"`
+	LastAmountCents    int64     `json:"lastAmountCents"`
+	FirstDate          time.Time `json:"firstDate"`
+	LastDate           time.Time `json:"lastDate"`
+	NextExpected       time.Time `json:"nextExpected"`
+	Active             bool      `json:"active"` // false once a due date passed without a booking
+
+	PriceChanges []PriceChange `json:"priceChanges,omitempty"`
+	TxUIDs       []string      `json:"txUids"`
+}
+
+type PriceChange struct {
+	Date      time.Time `json:"date"`
+	FromCents int64     `json:"fromCents"`
+	ToCents   int64     `json:"toCents"`
+}
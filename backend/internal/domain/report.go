@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+const (
+	ReportByCategory  = "category"
+	ReportByMerchant  = "merchant"
+	ReportByAccount   = "account"
+	ReportByDirection = "direction"
+
+	IntervalDay   = "day"
+	IntervalWeek  = "week" // ISO weeks, starting Monday
+	IntervalMonth = "month"
+	IntervalYear  = "year"
+)
+
+// Report sums bookings per period and group, next to the same group's
+// numbers for the period before and for the same period a year earlier.
+type Report struct {
+	GroupBy  string      `json:"groupBy"`
+	Interval string      `json:"interval"`
+	From     time.Time   `json:"from"`
+	To       time.Time   `json:"to"` // exclusive
+	Rows     []ReportRow `json:"rows"`
+}
+
+type ReportRow struct {
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"` // exclusive
+	Key         string    `json:"key"`
+	Currency    string    `json:"currency"`
+
+	Totals   ReportTotals `json:"totals"`
+	Previous ReportTotals `json:"previous"` // the period before
+	LastYear ReportTotals `json:"lastYear"` // same period one year earlier
+}
+
+type ReportTotals struct {
+	InCents  int64 `json:"inCents"`
+	OutCents int64 `json:"outCents"` // positive
+	NetCents int64 `json:"netCents"`
+	Count    int   `json:"count"`
+}
@@ -0,0 +1,7 @@
+package domain
+
+// SearchHit is a booking found by full-text search.
+type SearchHit struct {
+	Score float64 `json:"score"`
+	Transaction
+}
@@ -1,6 +1,14 @@
 package domain
 
+import "time"
+
+// TemplateSchemaVersion is the format version this build writes; older
+// documents are upgraded by template.Migrate.
+const TemplateSchemaVersion = 2
+
 type BankTemplate struct {
+	SchemaVersion int `json:"schemaVersion"`
+
 	ID   string `json:"id"`
 	Name string `json:"name"`
 
@@ -8,20 +16,63 @@ type BankTemplate struct {
 	Type string `json:"type"`
 
 	CSV CSVTemplate `json:"csv"`
+
+	// set by the store: every upsert or rollback is a new revision
+	Revision  int       `json:"revision"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
 type CSVTemplate struct {
-	Delimiter    string `json:"delimiter"` // "," ";" "\t"
+	Delimiter    string `json:"delimiter"` // "," ";" or a tab character
 	HasHeader    bool   `json:"hasHeader"`
 	HeaderSearch bool   `json:"headerSearch"` // NEW: scan for header row (skips preamble)
 	SkipRows     int    `json:"skipRows"`
 	EncodingHint string `json:"encodingHint"` // MVP: not used yet
 
-	DateFormats  []string `json:"dateFormats"`  // e.g. ["02.01.2006","2006-01-02"]
+	DateFormats  []string `json:"dateFormats"`  // e.g. ["02.01.2006","2006-01-02"], or "excel" for serial numbers
 	Decimal      string   `json:"decimal"`      // "de" or "en"
 	ThousandsSep string   `json:"thousandsSep"` // "." in de, "," in en
+	// two-digit years below this are 20xx, others 19xx; default 70
+	YearPivot int `json:"yearPivot,omitempty"`
+	// keep the time of day of datetime cells as Transaction.TransactionTime
+	KeepTime bool `json:"keepTime,omitempty"`
+	// how amounts with more digits than the currency's minor unit are
+	// stored: "halfUp" (default), "halfEven" or "down"; see util.RoundingMode
+	Rounding string `json:"rounding,omitempty"`
+	// ISO 4217 code for files without a currency column; default EUR
+	Currency string `json:"currency,omitempty"`
+	// IANA zone the bank's dates and times are in, e.g. "America/New_York";
+	// the account's timezone wins, the server default applies when both are empty
+	Timezone string `json:"timezone,omitempty"`
 
 	Columns CSVColumns `json:"columns"`
+
+	// a row is imported if it matches every Include and no Exclude
+	// predicate, e.g. to drop subtotal lines
+	Include []RowPredicate `json:"include,omitempty"`
+	Exclude []RowPredicate `json:"exclude,omitempty"`
+	// parsing stops before the first row matching any of these, e.g. a
+	// trailing "Kontostand" footer
+	EndOfData []RowPredicate `json:"endOfData,omitempty"`
+
+	// status cells (columns.status) marking a pending booking, compared
+	// case-insensitively; any other value means booked. Defaults to
+	// DefaultPendingValues.
+	PendingValues []string `json:"pendingValues,omitempty"`
+}
+
+// DefaultPendingValues covers the status wording of common exports.
+var DefaultPendingValues = []string{"pending", "vorgemerkt", "ausstehend", "nicht gebucht"}
+
+// RowPredicate tests one cell of a raw CSV row (all set conditions must
+// hold). Without Column it holds if any cell of the row matches.
+type RowPredicate struct {
+	Column     string `json:"column,omitempty"` // header name, or col_N without a header row
+	Equals     string `json:"equals,omitempty"`
+	Contains   string `json:"contains,omitempty"`
+	Pattern    string `json:"pattern,omitempty"` // RE2
+	Empty      bool   `json:"empty,omitempty"`   // the cell is blank or missing
+	IgnoreCase bool   `json:"ignoreCase,omitempty"`
 }
 
 type CSVColumns struct {
@@ -34,4 +85,28 @@ type CSVColumns struct {
 	MemoFields  []string `json:"memoFields"`  // NEW: combine multiple memo columns
 	Reference   string   `json:"reference"`   // optional
 	Iban        string   `json:"iban"`        // optional
+	Balance     string   `json:"balance"`     // optional, running balance after the booking ("Saldo")
+	Status      string   `json:"status"`      // optional, e.g. "Status"; see CSVTemplate.PendingValues
+
+	// per field (keyed by the JSON names above), steps applied in order to
+	// the cell before it is parsed, e.g. {"payee": [{"op": "stripPrefix", "values": ["VISA "]}]}
+	Transforms map[string][]Transform `json:"transforms,omitempty"`
+}
+
+// Transform is one step of a field's transform chain; see importer/transform
+// for the ops and which options each one reads.
+type Transform struct {
+	Op   string `json:"op"`
+	From string `json:"from,omitempty"` // start from another field's cell instead of the running value
+
+	Pattern    string            `json:"pattern,omitempty"` // regexExtract, regexReplace
+	Replace    string            `json:"replace,omitempty"` // regexReplace, $1 expands groups
+	Group      int               `json:"group,omitempty"`   // regexExtract; default 1 if the pattern has groups
+	Values     []string          `json:"values,omitempty"`  // stripPrefix, stripSuffix
+	IgnoreCase bool              `json:"ignoreCase,omitempty"`
+	Chars      string            `json:"chars,omitempty"` // trim; default whitespace
+	Sep        string            `json:"sep,omitempty"`   // split
+	Index      int               `json:"index,omitempty"` // split; negative counts from the end
+	Map        map[string]string `json:"map,omitempty"`   // map
+	Default    *string           `json:"default,omitempty"`
 }
@@ -2,24 +2,67 @@ package domain
 
 import "time"
 
+const (
+	CategoryUncategorized = "uncategorized"
+	CategoryTransfer      = "transfer" // money moving between own accounts; not income/outcome
+)
+
+const (
+	TxStatusBooked  = "booked"
+	TxStatusPending = "pending" // not yet settled; replaced by its booked version on a later import
+)
+
 type Transaction struct {
-	TenantID  string
-	AccountID string
-	BankID    string
+	TenantID  string `json:"tenantId"`
+	AccountID string `json:"accountId"`
+	BankID    string `json:"bankId"`
+
+	BookingDate time.Time  `json:"bookingDate"`
+	ValueDate   *time.Time `json:"valueDate,omitempty"`
+	// exact booking time when the export has one (Revolut, PayPal) and the
+	// template sets keepTime; places the Influx point instead of a hash offset
+	TransactionTime *time.Time `json:"transactionTime,omitempty"`
+
+	AmountCents  int64  `json:"amountCents"` // minor units of Currency per ISO 4217 (yen for JPY, fils for KWD)
+	Currency     string `json:"currency"`
+	Direction    string `json:"direction"`              // "in"|"out"
+	Status       string `json:"status"`                 // TxStatusBooked or TxStatusPending
+	BalanceCents *int64 `json:"balanceCents,omitempty"` // account balance after this booking, if the export has it
+
+	// the exact amount as written, when it has more fractional digits than
+	// Currency's minor unit (crypto, FX legs); AmountCents is rounded
+	AmountDecimal string `json:"amountDecimal,omitempty"`
+
+	Payee     string `json:"payee"`
+	Merchant  string `json:"merchant,omitempty"` // normalized payee ("VISA ALDI NORD" -> "Aldi")
+	Memo      string `json:"memo"`
+	Reference string `json:"reference,omitempty"`
+	IBAN      string `json:"iban,omitempty"`
+
+	// card purchases (parsed from the memo, see importer/memo)
+	CardLast4       string     `json:"cardLast4,omitempty"`
+	MerchantCity    string     `json:"merchantCity,omitempty"`
+	MerchantCountry string     `json:"merchantCountry,omitempty"` // ISO 3166 alpha-2
+	PurchaseDate    *time.Time `json:"purchaseDate,omitempty"`    // when the card was used; BookingDate can lag by days
+	ARN             string     `json:"arn,omitempty"`             // acquirer reference number
+
+	// foreign-currency payments; zero values when booked in account currency
+	OriginalAmountCents   int64   `json:"originalAmountCents,omitempty"`   // signed like AmountCents, in minor units of OriginalCurrency
+	OriginalAmountDecimal string  `json:"originalAmountDecimal,omitempty"` // like AmountDecimal
+	OriginalCurrency      string  `json:"originalCurrency,omitempty"`      // ISO 4217
+	FXRate                float64 `json:"fxRate,omitempty"`                // OriginalCurrency units per 1 Currency unit
+	FXFeeCents            int64   `json:"fxFeeCents,omitempty"`            // foreign usage fee charged by the bank, positive
 
-	BookingDate time.Time
-	ValueDate   *time.Time
+	CategoryID string `json:"categoryId"` // "uncategorized" unless detected, e.g. CategoryTransfer
 
-	AmountCents int64
-	Currency    string
-	Direction   string // "in"|"out"
+	// internal transfers: the other leg on one of our own accounts
+	TransferPeerUID   string `json:"transferPeerUid,omitempty"`
+	TransferAccountID string `json:"transferAccountId,omitempty"`
 
-	Payee     string
-	Memo      string
-	Reference string
-	IBAN      string
+	TxUID string `json:"txUid"` // stable hash
 
-	CategoryID string // MVP: always "uncategorized"
+	Flags []string `json:"flags,omitempty"` // anomaly kinds raised for this booking, e.g. AnomalyDuplicateCharge
 
-	TxUID string // stable hash
+	ImportBatchID string `json:"importBatchId,omitempty"` // the import that last wrote this booking
+	ReplacesUID   string `json:"replacesUid,omitempty"`   // the pending booking this booked one replaced
 }
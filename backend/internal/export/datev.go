@@ -0,0 +1,273 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"bankdash/backend/internal/domain"
+)
+
+// DATEV Buchungsstapel, format EXTF 700 / category 21 / version 13.
+const (
+	datevVersion       = 700
+	datevCategory      = 21
+	datevFormatVersion = 13
+	datevTextLen       = 60
+	datevDocFieldLen   = 36
+)
+
+var datevDefaults = map[string]struct{ bank, fallback, transit string }{
+	domain.SKR03: {bank: "1200", fallback: "1590", transit: "1360"},
+	domain.SKR04: {bank: "1800", fallback: "1370", transit: "1460"},
+}
+
+// DatevDefaults fills unset settings.
+func DatevDefaults(s domain.DatevSettings) domain.DatevSettings {
+	if s.ChartOfAccounts == "" {
+		s.ChartOfAccounts = domain.SKR03
+	}
+	if s.AccountLength == 0 {
+		s.AccountLength = 4
+	}
+	if s.FiscalYearStart == 0 {
+		s.FiscalYearStart = 1
+	}
+	if s.DefaultAccount == "" {
+		s.DefaultAccount = datevDefaults[s.ChartOfAccounts].fallback
+	}
+	if s.DocumentDate == "" {
+		s.DocumentDate = domain.DatevDateBooking
+	}
+	if s.TextTemplate == "" {
+		s.TextTemplate = "{merchant} {memo}"
+	}
+	return s
+}
+
+func ValidateDatev(s domain.DatevSettings) error {
+	if _, ok := datevDefaults[s.ChartOfAccounts]; !ok {
+		return fmt.Errorf("chartOfAccounts must be %s or %s", domain.SKR03, domain.SKR04)
+	}
+	if s.AccountLength < 4 || s.AccountLength > 8 {
+		return fmt.Errorf("accountLength must be 4..8")
+	}
+	if s.FiscalYearStart < 1 || s.FiscalYearStart > 12 {
+		return fmt.Errorf("fiscalYearStart must be a month 1..12")
+	}
+	switch s.DocumentDate {
+	case domain.DatevDateBooking, domain.DatevDateValue, domain.DatevDatePurchase:
+	default:
+		return fmt.Errorf("unknown documentDate: %q", s.DocumentDate)
+	}
+	check := func(what, acc string) error {
+		if _, err := strconv.Atoi(acc); err != nil || acc == "" {
+			return fmt.Errorf("%s: account %q is not a number", what, acc)
+		}
+		return nil
+	}
+	if err := check("defaultAccount", s.DefaultAccount); err != nil {
+		return err
+	}
+	for id, acc := range s.BankAccounts {
+		if err := check("bankAccounts."+id, acc); err != nil {
+			return err
+		}
+	}
+	for id, m := range s.Categories {
+		if err := check("categories."+id, m.Account); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FiscalYear returns the [start, end) fiscal year containing t.
+func FiscalYear(s domain.DatevSettings, t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), time.Month(s.FiscalYearStart), 1, 0, 0, 0, 0, time.UTC)
+	if t.Before(start) {
+		start = start.AddDate(-1, 0, 0)
+	}
+	return start, start.AddDate(1, 0, 0)
+}
+
+// datev writes a Buchungsstapel in Windows-1252, which is what the DATEV
+// import expects. From and To (exclusive) of Options become the batch
+// period; DATEV wants one batch per fiscal year.
+type datev struct {
+	w      *bufio.Writer
+	cfg    domain.DatevSettings
+	opts   Options
+	header bool
+}
+
+func newDatev(w io.Writer, opts Options) (*datev, error) {
+	if opts.Datev == nil {
+		return nil, fmt.Errorf("datev export needs settings")
+	}
+	if opts.From.IsZero() || opts.To.IsZero() {
+		return nil, fmt.Errorf("datev export needs from and to")
+	}
+	cfg := DatevDefaults(*opts.Datev)
+	if err := ValidateDatev(cfg); err != nil {
+		return nil, err
+	}
+	if fyStart, fyEnd := FiscalYear(cfg, opts.From); opts.To.After(fyEnd) {
+		return nil, fmt.Errorf("datev export must stay within one fiscal year (%s to %s)",
+			fyStart.Format("2006-01-02"), fyEnd.AddDate(0, 0, -1).Format("2006-01-02"))
+	}
+	return &datev{w: bufio.NewWriter(win1252{w}), cfg: cfg, opts: opts}, nil
+}
+
+var datevColumns = []string{
+	"Umsatz (ohne Soll/Haben-Kz)", "Soll/Haben-Kennzeichen", "WKZ Umsatz", "Kurs", "Basis-Umsatz", "WKZ Basis-Umsatz",
+	"Konto", "Gegenkonto (ohne BU-Schlüssel)", "BU-Schlüssel", "Belegdatum", "Belegfeld 1", "Belegfeld 2",
+	"Skonto", "Buchungstext",
+}
+
+func (d *datev) writeHeader() {
+	d.header = true
+	fyStart, _ := FiscalYear(d.cfg, d.opts.From)
+	fields := []string{
+		q("EXTF"), strconv.Itoa(datevVersion), strconv.Itoa(datevCategory), q("Buchungsstapel"), strconv.Itoa(datevFormatVersion),
+		time.Now().Format("20060102150405") + "000", "", q("RE"), q("bankdash"), q(""),
+		strconv.Itoa(d.cfg.ConsultantNumber), strconv.Itoa(d.cfg.ClientNumber),
+		fyStart.Format("20060102"), strconv.Itoa(d.cfg.AccountLength),
+		d.opts.From.Format("20060102"), d.opts.To.AddDate(0, 0, -1).Format("20060102"),
+		q("bankdash " + d.opts.From.Format("2006-01-02") + " - " + d.opts.To.AddDate(0, 0, -1).Format("2006-01-02")),
+		q(""), "1", "0", "0", q("EUR"), "", q(""), "", "", q(strings.TrimPrefix(d.cfg.ChartOfAccounts, "SKR")), "", "", q(""), q(""),
+	}
+	d.w.WriteString(strings.Join(fields, ";") + "\r\n")
+	cols := make([]string, len(datevColumns))
+	for i, c := range datevColumns {
+		cols[i] = q(c)
+	}
+	d.w.WriteString(strings.Join(cols, ";") + "\r\n")
+}
+
+func (d *datev) Write(tx domain.Transaction) error {
+	if !d.header {
+		d.writeHeader()
+	}
+	if tx.AmountCents == 0 {
+		return nil
+	}
+	if bookedByPeer(tx, d.opts) {
+		return nil
+	}
+
+	// seen from the bank account: money in is Soll, money out is Haben
+	sh, amount := "S", tx.AmountCents
+	if amount < 0 {
+		sh, amount = "H", -amount
+	}
+	counter, taxKey := d.counter(tx)
+	fields := []string{
+		Amount(amount, tx.Currency, ","), q(sh), q(tx.Currency), "", "", "",
+		d.bankAccount(tx.AccountID), counter, q(taxKey), d.documentDate(tx).Format("0201"),
+		q(docField(tx.Reference)), q(""), "", q(d.text(tx)),
+	}
+	_, err := d.w.WriteString(strings.Join(fields, ";") + "\r\n")
+	return err
+}
+
+func (d *datev) Close() error {
+	if !d.header {
+		d.writeHeader()
+	}
+	return d.w.Flush()
+}
+
+func (d *datev) bankAccount(accountID string) string {
+	if a := d.cfg.BankAccounts[accountID]; a != "" {
+		return a
+	}
+	return datevDefaults[d.cfg.ChartOfAccounts].bank
+}
+
+func (d *datev) counter(tx domain.Transaction) (string, string) {
+	if tx.CategoryID == domain.CategoryTransfer {
+		if a := d.cfg.BankAccounts[tx.TransferAccountID]; a != "" {
+			return a, ""
+		}
+		return datevDefaults[d.cfg.ChartOfAccounts].transit, ""
+	}
+	if m, ok := d.cfg.Categories[tx.CategoryID]; ok {
+		return m.Account, m.TaxKey
+	}
+	return d.cfg.DefaultAccount, ""
+}
+
+func (d *datev) documentDate(tx domain.Transaction) time.Time {
+	switch {
+	case d.cfg.DocumentDate == domain.DatevDateValue && tx.ValueDate != nil:
+		return *tx.ValueDate
+	case d.cfg.DocumentDate == domain.DatevDatePurchase && tx.PurchaseDate != nil:
+		return *tx.PurchaseDate
+	}
+	return tx.BookingDate
+}
+
+func (d *datev) text(tx domain.Transaction) string {
+	merchant := tx.Merchant
+	if merchant == "" {
+		merchant = tx.Payee
+	}
+	s := strings.NewReplacer(
+		"{payee}", tx.Payee, "{merchant}", merchant, "{memo}", tx.Memo,
+		"{reference}", tx.Reference, "{category}", tx.CategoryID,
+	).Replace(d.cfg.TextTemplate)
+	return truncate(oneLine(s), datevTextLen)
+}
+
+// docField keeps what Belegfeld 1 allows: A-Z, 0-9 and $&%*+-/.
+func docField(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || strings.ContainsRune("$&%*+-/", r) {
+			b.WriteRune(r)
+		}
+	}
+	return truncate(b.String(), datevDocFieldLen)
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// q quotes a DATEV text field; embedded quotes are doubled.
+func q(s string) string { return `"` + strings.ReplaceAll(s, `"`, `""`) + `"` }
+
+// win1252 transcodes UTF-8 to Windows-1252; runes it lacks become '?'.
+type win1252 struct{ w io.Writer }
+
+var win1252Extra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88, '‰': 0x89,
+	'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95,
+	'–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+func (e win1252) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p))
+	for _, r := range string(p) {
+		switch {
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			out = append(out, byte(r))
+		case win1252Extra[r] != 0:
+			out = append(out, win1252Extra[r])
+		default:
+			out = append(out, '?')
+		}
+	}
+	if _, err := e.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
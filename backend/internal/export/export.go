@@ -0,0 +1,264 @@
+// Package export writes normalized transactions in bank-independent formats.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/util"
+)
+
+const (
+	FormatCSV       = "csv"
+	FormatJSON      = "json"
+	FormatNDJSON    = "ndjson"
+	FormatBeancount = "beancount"
+	FormatHledger   = "hledger"
+	FormatLedger    = "ledger"
+	FormatDatev     = "datev"
+)
+
+// Options shape an export beyond its format.
+type Options struct {
+	Locale Locale
+
+	// plain-text accounting: journal account per bank account_id and per
+	// category_id; unmapped ones get a name derived from the id
+	LedgerAccounts  map[string]string
+	LedgerNames     map[string]string // account_id -> display name for derived names
+	CategoryAccount map[string]string
+	// Exported reports whether the booking with a tx_uid is part of this
+	// export, so a transfer whose both legs are exported is booked once
+	Exported func(txUID string) bool
+
+	// DATEV: settings and the batch period [From, To)
+	Datev    *domain.DatevSettings
+	From, To time.Time
+}
+
+// Locale controls number and date formatting in text formats.
+type Locale struct {
+	Name       string
+	Decimal    string // decimal separator
+	Delimiter  rune   // CSV field separator
+	DateLayout string
+}
+
+var locales = map[string]Locale{
+	"en": {Name: "en", Decimal: ".", Delimiter: ',', DateLayout: "2006-01-02"},
+	// what German spreadsheets and tax software open without an import dialog
+	"de": {Name: "de", Decimal: ",", Delimiter: ';', DateLayout: "02.01.2006"},
+}
+
+// LocaleFor returns the named locale; "" means en.
+func LocaleFor(name string) (Locale, error) {
+	if name == "" {
+		name = "en"
+	}
+	l, ok := locales[strings.ToLower(name)]
+	if !ok {
+		return Locale{}, fmt.Errorf("unknown locale: %q (want de or en)", name)
+	}
+	return l, nil
+}
+
+// Writer receives transactions one at a time; Close completes the document
+// and must be called once at the end.
+type Writer interface {
+	Write(tx domain.Transaction) error
+	Close() error
+}
+
+// New returns a Writer for format. JSON and NDJSON carry domain.Transaction
+// as is (amounts in minor units), so the locale only shapes CSV.
+func New(w io.Writer, format string, opts Options) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Comma = opts.Locale.Delimiter
+		return &csvWriter{w: cw, loc: opts.Locale}, nil
+	case FormatJSON:
+		return &jsonWriter{w: w}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case FormatBeancount, FormatHledger, FormatLedger:
+		return newJournal(w, format, opts), nil
+	case FormatDatev:
+		d, err := newDatev(w, opts)
+		if err != nil {
+			return nil, err
+		}
+		return d, nil
+	default:
+		return nil, fmt.Errorf("unknown format: %q", format)
+	}
+}
+
+// DoubleEntry reports whether format books both sides of a transaction, so
+// its Options need Exported.
+func DoubleEntry(format string) bool {
+	switch format {
+	case FormatBeancount, FormatHledger, FormatLedger, FormatDatev:
+		return true
+	default:
+		return false
+	}
+}
+
+// bookedByPeer reports whether tx is the incoming leg of a transfer whose
+// outgoing leg is in the same export; that leg already books both sides.
+func bookedByPeer(tx domain.Transaction, opts Options) bool {
+	return tx.CategoryID == domain.CategoryTransfer && tx.TransferPeerUID != "" && tx.AmountCents > 0 &&
+		opts.Exported != nil && opts.Exported(tx.TransferPeerUID)
+}
+
+// ContentType and Extension describe the file a format produces.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatBeancount, FormatHledger, FormatLedger:
+		return "text/plain; charset=utf-8"
+	case FormatDatev:
+		return "text/csv; charset=windows-1252"
+	default:
+		return "application/json"
+	}
+}
+
+func Extension(format string) string {
+	switch format {
+	case FormatHledger:
+		return "journal"
+	case FormatDatev:
+		return "csv"
+	default:
+		return format
+	}
+}
+
+var csvHeader = []string{
+	"tx_uid", "account_id", "bank_id", "booking_date", "value_date", "purchase_date",
+	"amount", "currency", "direction", "balance",
+	"payee", "merchant", "memo", "reference", "iban", "category_id",
+	"card_last4", "merchant_city", "merchant_country", "arn",
+	"original_amount", "original_currency", "fx_rate", "fx_fee",
+	"transfer_account_id", "transfer_peer_uid", "flags",
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	loc    Locale
+	header bool
+}
+
+func (c *csvWriter) Write(tx domain.Transaction) error {
+	if !c.header {
+		c.header = true
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	l := c.loc
+	amount := Amount(tx.AmountCents, tx.Currency, l.Decimal)
+	if tx.AmountDecimal != "" {
+		amount = strings.Replace(tx.AmountDecimal, ".", l.Decimal, 1)
+	}
+	var balance, purchase, orig, rate, fee string
+	if tx.BalanceCents != nil {
+		balance = Amount(*tx.BalanceCents, tx.Currency, l.Decimal)
+	}
+	if tx.PurchaseDate != nil {
+		// keeps the time of day, which matters for duplicate charges
+		purchase = tx.PurchaseDate.Format(l.DateLayout + " 15:04:05")
+	}
+	if tx.OriginalCurrency != "" {
+		orig = Amount(tx.OriginalAmountCents, tx.OriginalCurrency, l.Decimal)
+		if tx.OriginalAmountDecimal != "" {
+			orig = strings.Replace(tx.OriginalAmountDecimal, ".", l.Decimal, 1)
+		}
+	}
+	if tx.FXRate != 0 {
+		rate = strings.Replace(strconv.FormatFloat(tx.FXRate, 'f', -1, 64), ".", l.Decimal, 1)
+	}
+	if tx.FXFeeCents != 0 {
+		fee = Amount(tx.FXFeeCents, tx.Currency, l.Decimal)
+	}
+	return c.w.Write([]string{
+		tx.TxUID, tx.AccountID, tx.BankID, tx.BookingDate.Format(l.DateLayout), date(tx.ValueDate, l.DateLayout), purchase,
+		amount, tx.Currency, tx.Direction, balance,
+		tx.Payee, tx.Merchant, tx.Memo, tx.Reference, tx.IBAN, tx.CategoryID,
+		tx.CardLast4, tx.MerchantCity, tx.MerchantCountry, tx.ARN,
+		orig, tx.OriginalCurrency, rate, fee,
+		tx.TransferAccountID, tx.TransferPeerUID, strings.Join(tx.Flags, ","),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	if !c.header {
+		c.header = true
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonWriter streams one array without holding it in memory.
+type jsonWriter struct {
+	w io.Writer
+	n int
+}
+
+func (j *jsonWriter) Write(tx domain.Transaction) error {
+	b, err := json.Marshal(tx)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if j.n == 0 {
+		sep = "[\n"
+	}
+	j.n++
+	if _, err := io.WriteString(j.w, sep); err != nil {
+		return err
+	}
+	_, err = j.w.Write(b)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	end := "\n]\n"
+	if j.n == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
+
+type ndjsonWriter struct{ enc *json.Encoder }
+
+func (n *ndjsonWriter) Write(tx domain.Transaction) error { return n.enc.Encode(tx) }
+func (n *ndjsonWriter) Close() error                      { return nil }
+
+// Amount renders minor units of currency as a plain decimal ("-1234.50" /
+// "-1234,50"), without thousands separators so it stays machine-readable.
+func Amount(minor int64, currency, decimal string) string {
+	return util.FormatMinor(minor, currency, decimal)
+}
+
+func date(t *time.Time, layout string) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(layout)
+}
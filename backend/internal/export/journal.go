@@ -0,0 +1,142 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"bankdash/backend/internal/domain"
+)
+
+// journal writes double-entry transactions for Beancount, hledger or ledger.
+// Each booking becomes the bank posting plus one counter posting on the
+// category (or, for transfers, on the other bank account). tx_uid goes into
+// metadata so two exports can be diffed.
+type journal struct {
+	w      *bufio.Writer
+	format string
+	opts   Options
+	opened map[string]bool
+}
+
+func newJournal(w io.Writer, format string, opts Options) *journal {
+	return &journal{w: bufio.NewWriter(w), format: format, opts: opts, opened: map[string]bool{}}
+}
+
+func (j *journal) Write(tx domain.Transaction) error {
+	if bookedByPeer(tx, j.opts) {
+		return nil
+	}
+	bank := j.bankAccount(tx.AccountID)
+	counter := j.counterAccount(tx)
+
+	if j.format == FormatBeancount {
+		// Beancount rejects postings to accounts that were never opened;
+		// it sorts directives by date, so an early open works from anywhere
+		var opens []string
+		for _, a := range []string{bank, counter} {
+			if !j.opened[a] {
+				j.opened[a] = true
+				opens = append(opens, a)
+			}
+		}
+		sort.Strings(opens)
+		for _, a := range opens {
+			fmt.Fprintf(j.w, "1970-01-01 open %s\n\n", a)
+		}
+	}
+
+	date := tx.BookingDate.Format("2006-01-02")
+	payee := tx.Merchant
+	if payee == "" {
+		payee = tx.Payee
+	}
+	payee = oneLine(payee)
+	memo := oneLine(tx.Memo)
+	amt := Amount(tx.AmountCents, tx.Currency, ".") + " " + tx.Currency
+	neg := Amount(-tx.AmountCents, tx.Currency, ".") + " " + tx.Currency
+
+	switch j.format {
+	case FormatBeancount:
+		fmt.Fprintf(j.w, "%s * %s %s\n", date, quote(payee), quote(memo))
+		fmt.Fprintf(j.w, "  tx_uid: %s\n", quote(tx.TxUID))
+		if tx.Reference != "" {
+			fmt.Fprintf(j.w, "  reference: %s\n", quote(oneLine(tx.Reference)))
+		}
+		fmt.Fprintf(j.w, "  %s  %s\n", bank, amt)
+		fmt.Fprintf(j.w, "  %s  %s\n\n", counter, neg)
+	default:
+		desc := payee
+		if j.format == FormatHledger && memo != "" {
+			// hledger reads "payee | note"
+			desc = payee + " | " + memo
+		}
+		fmt.Fprintf(j.w, "%s * %s\n", date, strings.ReplaceAll(desc, ";", ","))
+		fmt.Fprintf(j.w, "    ; tx_uid: %s\n", tx.TxUID)
+		if j.format == FormatLedger && memo != "" {
+			fmt.Fprintf(j.w, "    ; %s\n", memo)
+		}
+		if tx.Reference != "" {
+			fmt.Fprintf(j.w, "    ; reference: %s\n", oneLine(tx.Reference))
+		}
+		fmt.Fprintf(j.w, "    %s  %s\n", bank, amt)
+		fmt.Fprintf(j.w, "    %s  %s\n\n", counter, neg)
+	}
+	return nil
+}
+
+func (j *journal) Close() error { return j.w.Flush() }
+
+func (j *journal) bankAccount(accountID string) string {
+	if a := j.opts.LedgerAccounts[accountID]; a != "" {
+		return a
+	}
+	name := j.opts.LedgerNames[accountID]
+	if name == "" {
+		name = accountID
+	}
+	return "Assets:Bank:" + accountComponent(name)
+}
+
+func (j *journal) counterAccount(tx domain.Transaction) string {
+	if tx.CategoryID == domain.CategoryTransfer && tx.TransferAccountID != "" {
+		return j.bankAccount(tx.TransferAccountID)
+	}
+	cat := tx.CategoryID
+	if cat == "" {
+		cat = domain.CategoryUncategorized
+	}
+	if a := j.opts.CategoryAccount[cat]; a != "" {
+		return a
+	}
+	root := "Expenses:"
+	if tx.AmountCents > 0 {
+		root = "Income:"
+	}
+	return root + accountComponent(cat)
+}
+
+// accountComponent turns an id into a valid account name part for all
+// three tools: ASCII letters, digits and dashes, starting upper-case.
+func accountComponent(s string) string {
+	s = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "Ä", "Ae", "Ö", "Oe", "Ü", "Ue", "ß", "ss").Replace(s)
+	var parts []string
+	for _, p := range strings.FieldsFunc(s, func(r rune) bool {
+		return r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	}) {
+		parts = append(parts, strings.ToUpper(p[:1])+p[1:])
+	}
+	if len(parts) == 0 {
+		return "Unknown"
+	}
+	return strings.Join(parts, "-")
+}
+
+func oneLine(s string) string { return strings.Join(strings.Fields(s), " ") }
+
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,133 @@
+package forecast
+
+import (
+	"sort"
+	"time"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/recurring"
+)
+
+// baselineDays is the history window for the per-category daily averages.
+const baselineDays = 90
+
+// Project forecasts the daily balance of one account for days days after
+// today, from its stored history (any order). Recurring series are placed on
+// their expected dates; everything else is spread as the per-category daily
+// average of the last baselineDays.
+func Project(accountID string, history []domain.Transaction, today time.Time, days int) domain.Forecast {
+	today = dateOf(today)
+	fc := domain.Forecast{AccountID: accountID, From: today, Days: days, Currency: "EUR"}
+
+	var txs []domain.Transaction
+	for _, tx := range history {
+		if tx.AccountID == accountID {
+			txs = append(txs, tx)
+		}
+	}
+	sort.SliceStable(txs, func(i, j int) bool { return txs[i].BookingDate.Before(txs[j].BookingDate) })
+	if len(txs) > 0 {
+		fc.Currency = txs[len(txs)-1].Currency
+	}
+	fc.StartBalance, fc.BalanceKnown = closingBalance(txs)
+
+	series := recurring.Detect(txs, today)
+	inSeries := map[string]bool{}
+	expected := map[time.Time]int64{}
+	horizon := today.AddDate(0, 0, days)
+	for _, s := range series {
+		for _, uid := range s.TxUIDs {
+			inSeries[uid] = true
+		}
+		if !s.Active {
+			continue
+		}
+		for due := dateOf(s.NextExpected); !due.After(horizon); due = dateOf(recurring.Next(s.Interval, due)) {
+			// overdue but still within grace: expect it right away
+			d := due
+			if d.Before(today) {
+				d = today
+			}
+			expected[d] += s.LastAmountCents
+			fc.Events = append(fc.Events, domain.ForecastExpected{
+				Date:        d,
+				Merchant:    s.Merchant,
+				AmountCents: s.LastAmountCents,
+				Interval:    s.Interval,
+			})
+		}
+	}
+	sort.SliceStable(fc.Events, func(i, j int) bool { return fc.Events[i].Date.Before(fc.Events[j].Date) })
+
+	baseline := dailyBaseline(txs, inSeries, today)
+
+	bal := fc.StartBalance
+	fc.MinBalanceCents, fc.MinBalanceDate = bal, today
+	for d := 1; d <= days; d++ {
+		day := today.AddDate(0, 0, d)
+		p := domain.ForecastPoint{Date: day, RecurringCents: expected[day], BaselineCents: baseline}
+		// overdue series were moved to today; book them on the first projected day
+		if d == 1 {
+			p.RecurringCents += expected[today]
+		}
+		bal += p.RecurringCents + p.BaselineCents
+		p.BalanceCents = bal
+		if bal < fc.MinBalanceCents {
+			fc.MinBalanceCents, fc.MinBalanceDate = bal, day
+		}
+		fc.Points = append(fc.Points, p)
+	}
+	return fc
+}
+
+// dailyBaseline sums the per-category average daily flow of bookings that
+// belong to no recurring series.
+func dailyBaseline(txs []domain.Transaction, inSeries map[string]bool, today time.Time) int64 {
+	from := today.AddDate(0, 0, -baselineDays)
+	perCategory := map[string]int64{}
+	for _, tx := range txs {
+		if inSeries[tx.TxUID] || tx.BookingDate.Before(from) || !tx.BookingDate.Before(today) {
+			continue
+		}
+		perCategory[tx.CategoryID] += tx.AmountCents
+	}
+	var total int64
+	for _, sum := range perCategory {
+		total += sum / baselineDays
+	}
+	return total
+}
+
+// closingBalance is the running balance after the last booking (txs sorted
+// by booking date). Bookings of one day have no reliable order, so the last
+// one is the balance no other same-day booking starts from.
+func closingBalance(txs []domain.Transaction) (int64, bool) {
+	var day []domain.Transaction
+	for i := len(txs) - 1; i >= 0; i-- {
+		if txs[i].BalanceCents == nil {
+			continue
+		}
+		if len(day) > 0 && !txs[i].BookingDate.Equal(day[0].BookingDate) {
+			break
+		}
+		day = append(day, txs[i])
+	}
+	if len(day) == 0 {
+		return 0, false
+	}
+	before := map[int64]bool{}
+	for _, tx := range day {
+		before[*tx.BalanceCents-tx.AmountCents] = true
+	}
+	for _, tx := range day {
+		if !before[*tx.BalanceCents] {
+			return *tx.BalanceCents, true
+		}
+	}
+	return *day[0].BalanceCents, true
+}
+
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,44 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"bankdash/backend/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *Server) handleListAccounts(w http.ResponseWriter, r *http.Request) {
+	list, err := s.meta.ListAccounts()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, list, 200)
+}
+
+func (s *Server) handleUpsertAccount(w http.ResponseWriter, r *http.Request) {
+	var a domain.Account
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	res := map[string]any{"ok": true, "id": a.ID}
+	if old, err := s.meta.GetAccount(a.ID); err == nil && old.Timezone != a.Timezone && s.importedWith("", a.ID) {
+		res["warning"] = tzChanged
+	}
+	if err := s.meta.UpsertAccount(a); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	writeJSON(w, res, 200)
+}
+
+func (s *Server) handleDeleteAccount(w http.ResponseWriter, r *http.Request) {
+	if err := s.meta.DeleteAccount(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true}, 200)
+}
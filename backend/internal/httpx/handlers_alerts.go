@@ -0,0 +1,241 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bankdash/backend/internal/alert"
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/influx"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *Server) handleListAlertRules(w http.ResponseWriter, r *http.Request) {
+	list, err := s.meta.ListAlertRules()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, list, 200)
+}
+
+func (s *Server) handleUpsertAlertRule(w http.ResponseWriter, r *http.Request) {
+	var rule domain.AlertRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	switch rule.Type {
+	case domain.AlertRuleBudget, domain.AlertRuleLargeTx, domain.AlertRuleNewPayee, domain.AlertRuleLowBalance:
+	default:
+		http.Error(w, "unknown alert rule type: "+rule.Type, 400)
+		return
+	}
+	if err := s.meta.UpsertAlertRule(rule); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true, "id": rule.ID}, 200)
+}
+
+func (s *Server) handleDeleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	if err := s.meta.DeleteAlertRule(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true}, 200)
+}
+
+func (s *Server) handleListAlertChannels(w http.ResponseWriter, r *http.Request) {
+	list, err := s.meta.ListAlertChannels()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	// don't hand secrets back out
+	for i := range list {
+		if list[i].Token != "" {
+			list[i].Token = "***"
+		}
+		if list[i].SMTP.Password != "" {
+			list[i].SMTP.Password = "***"
+		}
+	}
+	writeJSON(w, list, 200)
+}
+
+func (s *Server) handleUpsertAlertChannel(w http.ResponseWriter, r *http.Request) {
+	var ch domain.AlertChannel
+	if err := json.NewDecoder(r.Body).Decode(&ch); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	// the list masks secrets; a masked or empty one keeps the stored value
+	if old, err := s.meta.GetAlertChannel(ch.ID); err == nil {
+		if ch.Token == "" || ch.Token == "***" {
+			ch.Token = old.Token
+		}
+		if ch.SMTP.Password == "" || ch.SMTP.Password == "***" {
+			ch.SMTP.Password = old.SMTP.Password
+		}
+	}
+	if _, err := alert.NewNotifier(ch); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if err := s.meta.UpsertAlertChannel(ch); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true, "id": ch.ID}, 200)
+}
+
+func (s *Server) handleDeleteAlertChannel(w http.ResponseWriter, r *http.Request) {
+	if err := s.meta.DeleteAlertChannel(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true}, 200)
+}
+
+// handleTestAlertChannel sends a test notification through one channel.
+func (s *Server) handleTestAlertChannel(w http.ResponseWriter, r *http.Request) {
+	ch, err := s.meta.GetAlertChannel(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	n, err := alert.NewNotifier(*ch)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	a := domain.Alert{
+		Key:      "test",
+		Type:     "test",
+		Severity: "info",
+		Title:    "bankdash test notification",
+		Message:  "If you can read this, channel " + ch.ID + " works.",
+		At:       time.Now(),
+	}
+	if err := n.Send(r.Context(), a); err != nil {
+		http.Error(w, "send failed: "+err.Error(), 502)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true}, 200)
+}
+
+// handleListAlerts returns the delivered alerts, newest first (?limit=, default 100).
+func (s *Server) handleListAlerts(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid limit", 400)
+			return
+		}
+		limit = n
+	}
+	list, err := s.meta.ListAlerts(limit)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, list, 200)
+}
+
+// runAlerts evaluates the alert rules after an import of txs into accountID
+// and delivers new alerts. Returns how many were delivered.
+func (s *Server) runAlerts(ctx context.Context, accountID string, txs []domain.Transaction) (int, error) {
+	rules, err := s.meta.ListAlertRules()
+	if err != nil {
+		return 0, err
+	}
+	need := map[string]bool{}
+	for _, r := range rules {
+		if r.Enabled {
+			need[r.Type] = true
+		}
+	}
+	if len(need) == 0 {
+		return 0, nil
+	}
+
+	in := alert.Input{AccountID: accountID, Batch: txs, Now: time.Now()}
+	if need[domain.AlertRuleBudget] {
+		budgets, err := s.meta.ListBudgets()
+		if err != nil {
+			return 0, err
+		}
+		if in.Budgets, err = s.budgetStatuses(ctx, budgets, in.Now); err != nil {
+			return 0, err
+		}
+	}
+	if need[domain.AlertRuleNewPayee] || need[domain.AlertRuleLargeTx] {
+		if in.KnownMerchants, in.FirstImport, err = s.knownMerchants(ctx, accountID, txs); err != nil {
+			return 0, err
+		}
+	}
+
+	var fresh []domain.Alert
+	for _, a := range alert.Evaluate(rules, in) {
+		seen, err := s.meta.AlertSent(a.Key)
+		if err != nil {
+			return 0, err
+		}
+		if !seen {
+			fresh = append(fresh, a)
+		}
+	}
+	if len(fresh) == 0 {
+		return 0, nil
+	}
+
+	channels, err := s.meta.ListAlertChannels()
+	if err != nil {
+		return 0, err
+	}
+	sent, sendErr := alert.Dispatch(ctx, rules, channels, fresh)
+	for _, a := range sent {
+		if err := s.meta.RecordAlert(a); err != nil {
+			return len(sent), err
+		}
+	}
+	return len(sent), sendErr
+}
+
+// knownMerchants collects the payees the account had before this batch, and
+// reports whether it had no bookings at all.
+func (s *Server) knownMerchants(ctx context.Context, accountID string, batch []domain.Transaction) (map[string]bool, bool, error) {
+	inBatch := map[string]bool{}
+	for _, tx := range batch {
+		inBatch[tx.TxUID] = true
+	}
+	recs, err := s.inflx.QueryTransactions(ctx, influx.TxFilter{
+		TenantID:   s.cfg.DefaultTenant,
+		AccountIDs: []string{accountID},
+		From:       time.Now().AddDate(-3, 0, 0),
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	known := map[string]bool{}
+	first := true
+	for _, rec := range recs {
+		if inBatch[rec.Tx.TxUID] {
+			continue
+		}
+		first = false
+		name := rec.Tx.Merchant
+		if name == "" {
+			name = strings.TrimSpace(rec.Tx.Payee)
+		}
+		known[strings.ToLower(name)] = true
+	}
+	return known, first, nil
+}
@@ -0,0 +1,97 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"bankdash/backend/internal/anomaly"
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/influx"
+)
+
+// anomalies compare against about a year of history
+const anomalyHistoryMonths = 13
+
+// handleAnomalies lists findings between ?from= and ?to= (YYYY-MM-DD,
+// default the last 30 days), optionally for one ?account_id=.
+func (s *Server) handleAnomalies(w http.ResponseWriter, r *http.Request) {
+	to := time.Now().AddDate(0, 0, 1)
+	from := time.Now().AddDate(0, 0, -30)
+	for name, dst := range map[string]*time.Time{"from": &from, "to": &to} {
+		if v := r.URL.Query().Get(name); v != "" {
+			d, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				http.Error(w, "invalid "+name+" (want YYYY-MM-DD)", 400)
+				return
+			}
+			*dst = d
+		}
+	}
+
+	f := influx.TxFilter{
+		TenantID: s.cfg.DefaultTenant,
+		From:     from.AddDate(0, -anomalyHistoryMonths, 0),
+		To:       to,
+	}
+	if acc := r.URL.Query().Get("account_id"); acc != "" {
+		f.AccountIDs = []string{acc}
+	}
+	recs, err := s.inflx.QueryTransactions(r.Context(), f)
+	if err != nil {
+		http.Error(w, "influx query failed: "+err.Error(), 500)
+		return
+	}
+
+	out := []domain.Anomaly{}
+	for _, a := range anomaly.Detect(txsOf(recs), from) {
+		if a.Time.Before(to) {
+			out = append(out, a)
+		}
+	}
+	writeJSON(w, out, 200)
+}
+
+// detectAnomalies flags the batch against the account's history and stores
+// every finding as a bank_anomaly annotation point.
+func (s *Server) detectAnomalies(ctx context.Context, accountID string, txs []domain.Transaction) (int, error) {
+	if len(txs) == 0 {
+		return 0, nil
+	}
+	from, _ := bookingRange(txs)
+	inBatch := map[string]bool{}
+	for _, tx := range txs {
+		inBatch[tx.TxUID] = true
+		// the pending version is about to be deleted; it is no duplicate
+		if tx.ReplacesUID != "" {
+			inBatch[tx.ReplacesUID] = true
+		}
+		if tx.PurchaseDate != nil && tx.PurchaseDate.Before(from) {
+			from = *tx.PurchaseDate
+		}
+	}
+
+	recs, err := s.inflx.QueryTransactions(ctx, influx.TxFilter{
+		TenantID:   s.cfg.DefaultTenant,
+		AccountIDs: []string{accountID},
+		From:       from.AddDate(0, -anomalyHistoryMonths, 0),
+	})
+	if err != nil {
+		return 0, err
+	}
+	all := append([]domain.Transaction(nil), txs...)
+	for _, rec := range recs {
+		if !inBatch[rec.Tx.TxUID] {
+			all = append(all, rec.Tx)
+		}
+	}
+
+	found := anomaly.Detect(all, from)
+	anomaly.Flag(txs, found)
+	for _, a := range found {
+		if err := s.inflx.WritePoint(ctx, influx.AnomalyPoint(s.cfg.DefaultTenant, a)); err != nil {
+			return 0, err
+		}
+	}
+	return len(found), nil
+}
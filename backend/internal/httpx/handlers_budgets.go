@@ -0,0 +1,127 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"bankdash/backend/internal/budget"
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/influx"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *Server) handleListBudgets(w http.ResponseWriter, r *http.Request) {
+	list, err := s.meta.ListBudgets()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, list, 200)
+}
+
+func (s *Server) handleUpsertBudget(w http.ResponseWriter, r *http.Request) {
+	var b domain.Budget
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if b.StartDay == 0 {
+		b.StartDay = 1
+	}
+	b.Currency = budget.Currency(b)
+	if b.Rollover == "" {
+		b.Rollover = domain.RolloverNone
+	}
+	if err := budget.Validate(b); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if err := s.meta.UpsertBudget(b); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true, "id": b.ID}, 200)
+}
+
+func (s *Server) handleDeleteBudget(w http.ResponseWriter, r *http.Request) {
+	if err := s.meta.DeleteBudget(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true}, 200)
+}
+
+// handleBudgetStatus reports spent vs. limit for the current period of every
+// budget, or of ?id=. ?at=YYYY-MM-DD picks another day than today.
+func (s *Server) handleBudgetStatus(w http.ResponseWriter, r *http.Request) {
+	at := time.Now()
+	if v := r.URL.Query().Get("at"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			http.Error(w, "invalid at (want YYYY-MM-DD)", 400)
+			return
+		}
+		at = d
+	}
+
+	var budgets []domain.Budget
+	if id := r.URL.Query().Get("id"); id != "" {
+		b, err := s.meta.GetBudget(id)
+		if err != nil {
+			http.Error(w, err.Error(), 404)
+			return
+		}
+		budgets = []domain.Budget{*b}
+	} else {
+		list, err := s.meta.ListBudgets()
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		budgets = list
+	}
+
+	out, err := s.budgetStatuses(r.Context(), budgets, at)
+	if err != nil {
+		http.Error(w, "influx query failed: "+err.Error(), 500)
+		return
+	}
+	writeJSON(w, out, 200)
+}
+
+func (s *Server) budgetStatuses(ctx context.Context, budgets []domain.Budget, at time.Time) ([]domain.BudgetStatus, error) {
+	out := []domain.BudgetStatus{}
+	if len(budgets) == 0 {
+		return out, nil
+	}
+
+	var from, to time.Time
+	for i, b := range budgets {
+		lb := budget.LookbackStart(b, at)
+		_, end := budget.Period(b, at)
+		if i == 0 || lb.Before(from) {
+			from = lb
+		}
+		if end.After(to) {
+			to = end
+		}
+	}
+	// booking dates are calendar days; the point time can be up to a day
+	// later (hash offset) or earlier (card purchase time)
+	recs, err := s.inflx.QueryTransactions(ctx, influx.TxFilter{
+		TenantID: s.cfg.DefaultTenant,
+		From:     from.AddDate(0, 0, -14),
+		To:       to.AddDate(0, 0, 2),
+	})
+	if err != nil {
+		return nil, err
+	}
+	txs := txsOf(recs)
+	for _, b := range budgets {
+		out = append(out, budget.Status(b, txs, at))
+	}
+	return out, nil
+}
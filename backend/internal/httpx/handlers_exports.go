@@ -0,0 +1,138 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/export"
+	"bankdash/backend/internal/influx"
+
+	"github.com/rs/zerolog/log"
+)
+
+// handleExportTransactions streams bookings as ?format=csv|json|ndjson
+// (default csv) with ?locale=de|en number and date formatting, or as a
+// beancount|hledger|ledger journal. Journal accounts come from the
+// registered accounts' ledgerAccount and repeatable
+// ?category_account=groceries=Expenses:Food. format=datev writes a DATEV
+// Buchungsstapel for one fiscal year and needs ?from= and ?to=. It takes
+// the same filters as /transactions, without paging.
+func (s *Server) handleExportTransactions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f, err := s.txFilterFromQuery(q)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	format := q.Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	loc, err := export.LocaleFor(q.Get("locale"))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	opts := export.Options{
+		Locale:          loc,
+		LedgerAccounts:  map[string]string{},
+		LedgerNames:     map[string]string{},
+		CategoryAccount: map[string]string{},
+	}
+	for _, v := range q["category_account"] {
+		cat, acc, ok := strings.Cut(v, "=")
+		if !ok || cat == "" || acc == "" {
+			http.Error(w, "invalid category_account (want category=Account:Name)", 400)
+			return
+		}
+		opts.CategoryAccount[cat] = acc
+	}
+	if format == export.FormatDatev {
+		cfg, err := s.meta.GetDatevSettings()
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		opts.Datev, opts.From, opts.To = &cfg, f.From, f.To
+	}
+	if export.DoubleEntry(format) {
+		// an incoming transfer leg is skipped only when its outgoing leg is
+		// exported too, which the date, status and account filters decide
+		outgoing := map[string]bool{}
+		err := s.inflx.StreamTransactions(r.Context(), f, func(rec influx.TxRecord) error {
+			if rec.Tx.TransferPeerUID != "" && rec.Tx.AmountCents < 0 {
+				outgoing[rec.Tx.TxUID] = true
+			}
+			return nil
+		})
+		if err != nil {
+			http.Error(w, "influx query failed: "+err.Error(), 500)
+			return
+		}
+		opts.Exported = func(txUID string) bool { return outgoing[txUID] }
+	}
+	accounts, err := s.meta.ListAccounts()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	for _, a := range accounts {
+		opts.LedgerAccounts[a.ID] = a.LedgerAccount
+		opts.LedgerNames[a.ID] = a.Name
+	}
+
+	ew, err := export.New(w, format, opts)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="transactions.`+export.Extension(format)+`"`)
+	started := false
+	err = s.inflx.StreamTransactions(r.Context(), f, func(rec influx.TxRecord) error {
+		started = true
+		return ew.Write(rec.Tx)
+	})
+	if err != nil && !started {
+		w.Header().Del("Content-Disposition")
+		http.Error(w, "influx query failed: "+err.Error(), 500)
+		return
+	}
+	if err == nil {
+		err = ew.Close()
+	}
+	// the status line is gone by now; all that is left is to cut the body short
+	if err != nil {
+		log.Warn().Err(err).Msg("transaction export failed")
+	}
+}
+
+func (s *Server) handleGetDatevSettings(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.meta.GetDatevSettings()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, export.DatevDefaults(cfg), 200)
+}
+
+func (s *Server) handlePutDatevSettings(w http.ResponseWriter, r *http.Request) {
+	var cfg domain.DatevSettings
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	cfg = export.DatevDefaults(cfg)
+	if err := export.ValidateDatev(cfg); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if err := s.meta.PutDatevSettings(cfg); err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true}, 200)
+}
@@ -0,0 +1,89 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/forecast"
+	"bankdash/backend/internal/influx"
+)
+
+const (
+	defaultForecastDays = 90
+	maxForecastDays     = 365
+	// history needed for recurring detection (yearly series) and baselines
+	forecastHistoryMonths = 24
+)
+
+// handleForecast projects daily balances for ?account_id= (default: every
+// account with data) over ?days= (default 90). ?write=true also stores the
+// projection as bank_forecast for Grafana.
+func (s *Server) handleForecast(w http.ResponseWriter, r *http.Request) {
+	days := defaultForecastDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxForecastDays {
+			http.Error(w, "invalid days (1..365)", 400)
+			return
+		}
+		days = n
+	}
+	var accountIDs []string
+	if acc := r.URL.Query().Get("account_id"); acc != "" {
+		accountIDs = []string{acc}
+	}
+
+	fcs, err := s.forecasts(r.Context(), accountIDs, days)
+	if err != nil {
+		http.Error(w, "influx query failed: "+err.Error(), 500)
+		return
+	}
+	if r.URL.Query().Get("write") == "true" {
+		if err := s.writeForecasts(r.Context(), fcs); err != nil {
+			http.Error(w, "influx write failed: "+err.Error(), 500)
+			return
+		}
+	}
+	writeJSON(w, fcs, 200)
+}
+
+func (s *Server) forecasts(ctx context.Context, accountIDs []string, days int) ([]domain.Forecast, error) {
+	recs, err := s.inflx.QueryTransactions(ctx, influx.TxFilter{
+		TenantID:   s.cfg.DefaultTenant,
+		AccountIDs: accountIDs,
+		From:       time.Now().AddDate(0, -forecastHistoryMonths, 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+	txs := txsOf(recs)
+	if len(accountIDs) == 0 {
+		seen := map[string]bool{}
+		for _, tx := range txs {
+			if !seen[tx.AccountID] {
+				seen[tx.AccountID] = true
+				accountIDs = append(accountIDs, tx.AccountID)
+			}
+		}
+		sort.Strings(accountIDs)
+	}
+
+	out := []domain.Forecast{}
+	for _, acc := range accountIDs {
+		out = append(out, forecast.Project(acc, txs, time.Now(), days))
+	}
+	return out, nil
+}
+
+func (s *Server) writeForecasts(ctx context.Context, fcs []domain.Forecast) error {
+	for _, fc := range fcs {
+		if err := s.inflx.WritePoints(ctx, influx.ForecastPoints(s.cfg.DefaultTenant, fc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,15 +2,25 @@ package httpx
 
 import (
 	"context"
-	"encoding/binary"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"net/http"
 	"time"
 
+	"bankdash/backend/internal/domain"
 	"bankdash/backend/internal/importer/csv"
+	"bankdash/backend/internal/influx"
+	"bankdash/backend/internal/merchant"
+	"bankdash/backend/internal/pending"
+	"bankdash/backend/internal/transfer"
 
-	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/rs/zerolog/log"
 )
 
+// alertTimeout bounds alert delivery after an import, all channels together.
+const alertTimeout = 30 * time.Second
+
 func (s *Server) handleImportCSV(w http.ResponseWriter, r *http.Request) {
 	templateID := r.URL.Query().Get("template_id")
 	accountID := r.URL.Query().Get("account_id")
@@ -33,88 +43,353 @@ func (s *Server) handleImportCSV(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), 400)
 		return
 	}
-	f, _, err := r.FormFile("file")
+	f, fh, err := r.FormFile("file")
 	if err != nil {
 		http.Error(w, "missing multipart file field 'file'", 400)
 		return
 	}
 	defer f.Close()
 
-	imp := csvimporter.New()
+	aliases, err := s.meta.ListMerchantAliases()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	loc, err := s.importLocation(*tmpl, accountID)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	imp := csvimporter.New(loc).WithMerchants(merchant.NewNormalizer(aliases))
 	txs, err := imp.Import(context.Background(), f, *tmpl, s.cfg.DefaultTenant, accountID, bankID)
 	if err != nil {
 		http.Error(w, err.Error(), 400)
 		return
 	}
 
+	batch := domain.ImportBatch{
+		ID:               newBatchID(),
+		TemplateID:       tmpl.ID,
+		TemplateRevision: tmpl.Revision,
+		AccountID:        accountID,
+		BankID:           bankID,
+		FileName:         fh.Filename,
+		Imported:         len(txs),
+		ImportedAt:       time.Now().UTC(),
+	}
+	for i := range txs {
+		txs[i].ImportBatchID = batch.ID
+	}
+
+	txs, settled, err := s.replacePending(context.Background(), accountID, txs)
+	if err != nil {
+		http.Error(w, "pending replacement failed: "+err.Error(), 500)
+		return
+	}
+	batch.Imported = len(txs)
+	batch.ReplacedPending = len(settled)
+
+	transfers, linked, err := s.detectTransfers(context.Background(), accountID, txs)
+	if err != nil {
+		http.Error(w, "transfer detection failed: "+err.Error(), 500)
+		return
+	}
+
+	anomalies, err := s.detectAnomalies(context.Background(), accountID, txs)
+	if err != nil {
+		http.Error(w, "anomaly detection failed: "+err.Error(), 500)
+		return
+	}
+
+	// a re-import after an alias or category change writes other tags
+	// than last time; the old point would stay next to the new one
+	stale, err := s.staleSeries(context.Background(), accountID, txs)
+	if err != nil {
+		http.Error(w, "influx query failed: "+err.Error(), 500)
+		return
+	}
+	for _, rec := range stale {
+		if err := s.inflx.DeleteTxPoint(context.Background(), rec.Tx.AccountID, rec.Time); err != nil {
+			http.Error(w, "influx delete failed: "+err.Error(), 500)
+			return
+		}
+	}
+
 	for _, tx := range txs {
-		// deterministic timestamp within the booking day:
-		// midnight + (first 8 bytes of txUID) mod 1 day
-		dayStart := tx.BookingDate
-		hashBytes := decodeFirst8(tx.TxUID)
-		offset := time.Duration(hashBytes % uint64(24*time.Hour)) // nanoseconds-ish, but duration is ns
-		ts := dayStart.Add(offset)
-
-		p := influxdb2.NewPoint(
-			"bank_tx",
-			map[string]string{
-				"tenant_id":   tx.TenantID,
-				"account_id":  tx.AccountID,
-				"bank_id":     tx.BankID,
-				"currency":    tx.Currency,
-				"direction":   tx.Direction,
-				"category_id": tx.CategoryID,
-			},
-			map[string]any{
-				"amount_cents":     tx.AmountCents,
-				"amount_cents_abs": abs64(tx.AmountCents),
-				"payee":            tx.Payee,
-				"memo":             tx.Memo,
-				"reference":        tx.Reference,
-				"iban":             tx.IBAN,
-				"tx_uid":           tx.TxUID,
-			},
-			ts,
-		)
-
-		if err := s.inflx.WritePoint(context.Background(), p); err != nil {
+		if err := s.inflx.WritePoint(context.Background(), influx.TxPoint(tx, influx.PointTime(tx))); err != nil {
 			http.Error(w, "influx write failed: "+err.Error(), 500)
 			return
 		}
 	}
 
-	writeJSON(w, map[string]any{"imported": len(txs)}, 200)
+	// stored legs and pending bookings change only now that the batch they
+	// point at is written
+	if err := s.rewriteTransferLegs(context.Background(), linked); err != nil {
+		http.Error(w, "transfer rewrite failed: "+err.Error(), 500)
+		return
+	}
+	if err := s.deletePending(context.Background(), settled); err != nil {
+		http.Error(w, "pending replacement failed: "+err.Error(), 500)
+		return
+	}
+
+	if err := s.meta.RecordImportBatch(batch); err != nil {
+		http.Error(w, "recording import batch failed: "+err.Error(), 500)
+		return
+	}
+
+	// indexing, alerting and forecasting must never fail an import that is
+	// already written; a lost index update is repaired by /search/reindex
+	if err := s.meta.IndexTransactions(txs); err != nil {
+		log.Warn().Err(err).Msg("search indexing failed")
+	}
+	// channels that do not answer must not hold the response forever
+	alertCtx, cancel := context.WithTimeout(r.Context(), alertTimeout)
+	alerts, err := s.runAlerts(alertCtx, accountID, txs)
+	cancel()
+	if err != nil {
+		log.Warn().Err(err).Msg("alerting failed")
+	}
+	if fcs, err := s.forecasts(context.Background(), []string{accountID}, defaultForecastDays); err != nil {
+		log.Warn().Err(err).Msg("forecast failed")
+	} else if err := s.writeForecasts(context.Background(), fcs); err != nil {
+		log.Warn().Err(err).Msg("forecast write failed")
+	}
+
+	writeJSON(w, map[string]any{"batchId": batch.ID, "templateRevision": batch.TemplateRevision,
+		"imported": len(txs), "replacedPending": batch.ReplacedPending, "transfers": transfers, "anomalies": anomalies, "alerts": alerts}, 200)
+}
+
+// detectTransfers links batch legs with already stored legs on our other
+// accounts. Returns the number of linked legs in the batch and the stored
+// legs that changed; rewriteTransferLegs writes those once the batch is in.
+func (s *Server) detectTransfers(ctx context.Context, accountID string, txs []domain.Transaction) (int, []influx.TxRecord, error) {
+	if len(txs) == 0 {
+		return 0, nil, nil
+	}
+	accounts, err := s.meta.ListAccounts()
+	if err != nil {
+		return 0, nil, err
+	}
+	m := transfer.NewMatcher(accounts, s.cfg.TransferWindowDays)
+	others := m.OtherAccounts(accountID)
+	if !m.Registered(accountID) || len(others) == 0 {
+		return 0, nil, nil
+	}
+
+	from, to := bookingRange(txs)
+	back := m.Window()
+	for _, tx := range txs {
+		// the peer of a settled pending booking is dated near that one
+		if tx.ReplacesUID != "" {
+			back += time.Duration(s.cfg.PendingWindowDays) * 24 * time.Hour
+			break
+		}
+	}
+	recs, err := s.inflx.QueryTransactions(ctx, influx.TxFilter{
+		TenantID:   s.cfg.DefaultTenant,
+		AccountIDs: others,
+		From:       from.Add(-back - 24*time.Hour),
+		To:         to.Add(m.Window() + 48*time.Hour),
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	stored := txsOf(recs)
+
+	var linked []influx.TxRecord
+	for _, i := range m.Match(txs, stored) {
+		linked = append(linked, influx.TxRecord{Time: recs[i].Time, Tx: stored[i]})
+	}
+
+	n := 0
+	for _, tx := range txs {
+		if tx.TransferPeerUID != "" {
+			n++
+		}
+	}
+	return n, linked, nil
+}
+
+// staleSeries returns the stored points of bookings in txs that the write
+// of txs would leave next to the new ones.
+func (s *Server) staleSeries(ctx context.Context, accountID string, txs []domain.Transaction) ([]influx.TxRecord, error) {
+	if len(txs) == 0 {
+		return nil, nil
+	}
+	from, to := bookingRange(txs)
+	// point times stay within a day of the booking date, whatever the zone
+	recs, err := s.inflx.QueryTransactions(ctx, influx.TxFilter{
+		TenantID:   s.cfg.DefaultTenant,
+		AccountIDs: []string{accountID},
+		From:       from.Add(-48 * time.Hour),
+		To:         to.Add(72 * time.Hour),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return staleRecords(txs, recs), nil
+}
+
+// staleRecords picks the recs of bookings in txs that writing txs does not
+// overwrite: other tags, or another point time.
+func staleRecords(txs []domain.Transaction, recs []influx.TxRecord) []influx.TxRecord {
+	byUID := make(map[string]domain.Transaction, len(txs))
+	for _, tx := range txs {
+		byUID[tx.TxUID] = tx
+	}
+	var out []influx.TxRecord
+	for _, rec := range recs {
+		tx, ok := byUID[rec.Tx.TxUID]
+		if ok && (!influx.SameSeries(rec.Tx, tx) || !rec.Time.Equal(influx.PointTime(tx))) {
+			out = append(out, rec)
+		}
+	}
+	return out
 }
-func abs64(v int64) int64 {
-	if v < 0 {
-		return -v
+
+// rewriteTransferLegs stores legs relinked by detectTransfers at their
+// original point time.
+func (s *Server) rewriteTransferLegs(ctx context.Context, recs []influx.TxRecord) error {
+	var rewritten []domain.Transaction
+	for _, rec := range recs {
+		// category_id is a tag, so the old point has to go before the rewrite
+		if err := s.inflx.DeleteTxPoint(ctx, rec.Tx.AccountID, rec.Time); err != nil {
+			return err
+		}
+		if err := s.inflx.WritePoint(ctx, influx.TxPoint(rec.Tx, rec.Time)); err != nil {
+			return err
+		}
+		rewritten = append(rewritten, rec.Tx)
 	}
-	return v
+	// search hits carry the category, keep them in step
+	return s.meta.IndexTransactions(rewritten)
 }
 
-func decodeFirst8(hexStr string) uint64 {
-	// hexStr is 64 chars (sha256). Take first 16 hex chars = 8 bytes.
-	if len(hexStr) < 16 {
-		return 0
+// importLocation picks the zone of the account, else the template's, else
+// the configured default.
+func (s *Server) importLocation(tmpl domain.BankTemplate, accountID string) (*time.Location, error) {
+	tz := s.cfg.DefaultTimezone
+	if tmpl.CSV.Timezone != "" {
+		tz = tmpl.CSV.Timezone
+	}
+	if acc, err := s.meta.GetAccount(accountID); err == nil && acc.Timezone != "" {
+		tz = acc.Timezone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
 	}
-	var buf [8]byte
-	for i := 0; i < 8; i++ {
-		hi := fromHex(hexStr[i*2])
-		lo := fromHex(hexStr[i*2+1])
-		buf[i] = (hi << 4) | lo
+	return loc, nil
+}
+
+// tzChanged is the warning for a timezone edit on an account or template
+// that imports already used: dates are placed at midnight in that zone, so
+// bookings read before the edit sit at other times and a re-import of the
+// same file adds them again.
+const tzChanged = "timezone changed after imports; re-importing files imported before will duplicate their bookings"
+
+// importedWith reports whether an import used the template or went into the
+// account; an empty id matches any.
+func (s *Server) importedWith(templateID, accountID string) bool {
+	batches, err := s.meta.ListImportBatches(templateID, 0)
+	if err != nil {
+		return false
+	}
+	for _, b := range batches {
+		if accountID == "" || b.AccountID == accountID {
+			return true
+		}
+	}
+	return false
+}
+
+// replacePending links booked entries of txs to the pending bookings they
+// settle (see pending.Matcher). It returns txs without pending entries that
+// are already replaced, and the stored pending bookings to delete once txs
+// is written.
+func (s *Server) replacePending(ctx context.Context, accountID string, txs []domain.Transaction) ([]domain.Transaction, []influx.TxRecord, error) {
+	if len(txs) == 0 {
+		return txs, nil, nil
+	}
+	m := pending.NewMatcher(s.cfg.PendingWindowDays)
+
+	from, to := bookingRange(txs)
+	// pending entries are older than their booked version, booked ones
+	// (carrying the link) newer than their pending one
+	recs, err := s.inflx.QueryTransactions(ctx, influx.TxFilter{
+		TenantID:   s.cfg.DefaultTenant,
+		AccountIDs: []string{accountID},
+		From:       from.Add(-m.Window() - 24*time.Hour),
+		To:         to.Add(m.Window() + 48*time.Hour),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	replaced, drop := m.Match(txs, txsOf(recs))
+	settled := make([]influx.TxRecord, 0, len(replaced))
+	for _, i := range replaced {
+		settled = append(settled, recs[i])
+	}
+	if len(drop) == 0 {
+		return txs, settled, nil
+	}
+	dropped := map[int]bool{}
+	for _, i := range drop {
+		dropped[i] = true
+	}
+	kept := make([]domain.Transaction, 0, len(txs)-len(drop))
+	for i, tx := range txs {
+		if !dropped[i] {
+			kept = append(kept, tx)
+		}
+	}
+	return kept, settled, nil
+}
+
+// deletePending removes replaced pending bookings from Influx and the search
+// index. A transfer link of theirs has moved to the booked version by now
+// (detectTransfers relinks the peer leg).
+func (s *Server) deletePending(ctx context.Context, recs []influx.TxRecord) error {
+	uids := make([]string, 0, len(recs))
+	for _, rec := range recs {
+		if err := s.inflx.DeleteTxPoint(ctx, rec.Tx.AccountID, rec.Time); err != nil {
+			return err
+		}
+		uids = append(uids, rec.Tx.TxUID)
+	}
+	return s.meta.RemoveFromIndex(uids)
+}
+
+// newBatchID is sortable by time; the random tail keeps parallel imports apart.
+func newBatchID() string {
+	var b [4]byte
+	_, _ = rand.Read(b[:])
+	return time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b[:])
+}
+
+// bookingRange is the first and last booking date of txs, which must not be
+// empty.
+func bookingRange(txs []domain.Transaction) (from, to time.Time) {
+	from, to = txs[0].BookingDate, txs[0].BookingDate
+	for _, tx := range txs {
+		if tx.BookingDate.Before(from) {
+			from = tx.BookingDate
+		}
+		if tx.BookingDate.After(to) {
+			to = tx.BookingDate
+		}
 	}
-	return binary.BigEndian.Uint64(buf[:])
+	return from, to
 }
 
-func fromHex(c byte) byte {
-	switch {
-	case c >= '0' && c <= '9':
-		return c - '0'
-	case c >= 'a' && c <= 'f':
-		return c - 'a' + 10
-	case c >= 'A' && c <= 'F':
-		return c - 'A' + 10
-	default:
-		return 0
+func txsOf(recs []influx.TxRecord) []domain.Transaction {
+	out := make([]domain.Transaction, len(recs))
+	for i, rec := range recs {
+		out[i] = rec.Tx
 	}
+	return out
 }
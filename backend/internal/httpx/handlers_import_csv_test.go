@@ -0,0 +1,69 @@
+package httpx
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/importer/csv"
+	"bankdash/backend/internal/influx"
+	"bankdash/backend/internal/merchant"
+)
+
+const aliasCSV = `Buchungstag;Betrag;Empfänger;Verwendungszweck
+02.01.2026;-12,50;MIX MARKT 54;Einkauf
+03.01.2026;-3,20;BAECKEREI KRAUSE;Brötchen
+`
+
+func importWithAliases(t *testing.T, aliases []domain.MerchantAlias) []domain.Transaction {
+	t.Helper()
+	tmpl := domain.BankTemplate{
+		ID:   "test",
+		Type: "csv",
+		CSV: domain.CSVTemplate{
+			Delimiter:   ";",
+			HasHeader:   true,
+			DateFormats: []string{"02.01.2006"},
+			Decimal:     "de",
+			Columns: domain.CSVColumns{
+				BookingDate: "Buchungstag",
+				Amount:      "Betrag",
+				Payee:       "Empfänger",
+				Memo:        "Verwendungszweck",
+			},
+		},
+	}
+	imp := csvimporter.New(time.UTC).WithMerchants(merchant.NewNormalizer(aliases))
+	txs, err := imp.Import(context.Background(), strings.NewReader(aliasCSV), tmpl, "default", "main", "bank")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return txs
+}
+
+func TestStaleRecordsAfterAliasChange(t *testing.T) {
+	first := importWithAliases(t, nil)
+	var stored []influx.TxRecord
+	for _, tx := range first {
+		stored = append(stored, influx.TxRecord{Time: influx.PointTime(tx), Tx: tx})
+	}
+
+	// same file, same aliases: every point is overwritten in place
+	if got := staleRecords(importWithAliases(t, nil), stored); len(got) != 0 {
+		t.Fatalf("re-import without changes: %d stale points, want 0", len(got))
+	}
+
+	again := importWithAliases(t, []domain.MerchantAlias{{Match: "MIX MARKT", Merchant: "Mixmarkt"}})
+	if again[0].TxUID != first[0].TxUID {
+		t.Fatalf("alias changed the tx_uid")
+	}
+	if again[0].Merchant == first[0].Merchant {
+		t.Fatalf("alias did not change the merchant (%q)", again[0].Merchant)
+	}
+	got := staleRecords(again, stored)
+	if len(got) != 1 || got[0].Tx.TxUID != first[0].TxUID || !got[0].Time.Equal(stored[0].Time) {
+		t.Fatalf("stale = %+v, want the first booking's old point", got)
+	}
+}
@@ -0,0 +1,37 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// handleListImports lists import batches newest first, optionally for one
+// ?template_id=, at most ?limit= (default 100).
+func (s *Server) handleListImports(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid limit", 400)
+			return
+		}
+		limit = n
+	}
+	list, err := s.meta.ListImportBatches(r.URL.Query().Get("template_id"), limit)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, list, 200)
+}
+
+func (s *Server) handleGetImport(w http.ResponseWriter, r *http.Request) {
+	b, err := s.meta.GetImportBatch(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	writeJSON(w, b, 200)
+}
@@ -0,0 +1,61 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/merchant"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *Server) handleListMerchantAliases(w http.ResponseWriter, r *http.Request) {
+	list, err := s.meta.ListMerchantAliases()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, list, 200)
+}
+
+func (s *Server) handleUpsertMerchantAlias(w http.ResponseWriter, r *http.Request) {
+	var a domain.MerchantAlias
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if err := s.meta.UpsertMerchantAlias(a); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true, "match": a.Match}, 200)
+}
+
+func (s *Server) handleDeleteMerchantAlias(w http.ResponseWriter, r *http.Request) {
+	match := chi.URLParam(r, "match")
+	if err := s.meta.DeleteMerchantAlias(match); err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true}, 200)
+}
+
+// handleNormalizeMerchant previews what a payee maps to with the current aliases.
+func (s *Server) handleNormalizeMerchant(w http.ResponseWriter, r *http.Request) {
+	payee := r.URL.Query().Get("payee")
+	if payee == "" {
+		http.Error(w, "missing payee", 400)
+		return
+	}
+	aliases, err := s.meta.ListMerchantAliases()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, map[string]any{
+		"payee":    payee,
+		"cleaned":  merchant.Clean(payee),
+		"merchant": merchant.NewNormalizer(aliases).Normalize(payee),
+	}, 200)
+}
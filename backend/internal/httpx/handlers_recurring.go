@@ -0,0 +1,49 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"bankdash/backend/internal/influx"
+	"bankdash/backend/internal/recurring"
+)
+
+// handleRecurring detects subscriptions and standing payments over the last
+// ?months= (default 24), optionally for one ?account_id=.
+func (s *Server) handleRecurring(w http.ResponseWriter, r *http.Request) {
+	months := 24
+	if v := r.URL.Query().Get("months"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid months", 400)
+			return
+		}
+		months = n
+	}
+
+	f := influx.TxFilter{
+		TenantID: s.cfg.DefaultTenant,
+		From:     time.Now().AddDate(0, -months, 0),
+	}
+	if acc := r.URL.Query().Get("account_id"); acc != "" {
+		f.AccountIDs = []string{acc}
+	}
+	recs, err := s.inflx.QueryTransactions(r.Context(), f)
+	if err != nil {
+		http.Error(w, "influx query failed: "+err.Error(), 500)
+		return
+	}
+
+	series := recurring.Detect(txsOf(recs), time.Now())
+	if r.URL.Query().Get("active") == "true" {
+		active := series[:0]
+		for _, rs := range series {
+			if rs.Active {
+				active = append(active, rs)
+			}
+		}
+		series = active
+	}
+	writeJSON(w, series, 200)
+}
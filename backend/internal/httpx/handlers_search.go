@@ -0,0 +1,53 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"bankdash/backend/internal/influx"
+)
+
+const defaultSearchLimit = 50
+
+// handleSearch runs a full-text query over payee, merchant, reference and
+// memo: ?q=rossmann, ?q="dauerauftrag miete", ?q=amaz* or a pasted order
+// number. ?account_id= (repeatable) narrows it, ?limit= caps the hits.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		http.Error(w, "missing q", 400)
+		return
+	}
+	limit := defaultSearchLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxTxPageSize {
+			http.Error(w, "invalid limit (1-"+strconv.Itoa(maxTxPageSize)+")", 400)
+			return
+		}
+		limit = n
+	}
+
+	hits, total, err := s.meta.Search(q, listParam(r.URL.Query()["account_id"]), limit)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, map[string]any{"total": total, "hits": hits}, 200)
+}
+
+// handleReindexSearch rebuilds the index from everything stored in Influx,
+// e.g. for bookings imported before the index existed.
+func (s *Server) handleReindexSearch(w http.ResponseWriter, r *http.Request) {
+	recs, err := s.inflx.QueryTransactions(r.Context(), influx.TxFilter{TenantID: s.cfg.DefaultTenant})
+	if err != nil {
+		http.Error(w, "influx query failed: "+err.Error(), 500)
+		return
+	}
+	if err := s.meta.IndexTransactions(txsOf(recs)); err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true, "indexed": len(recs)}, 200)
+}
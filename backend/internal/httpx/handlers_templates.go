@@ -2,9 +2,14 @@ package httpx
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 
-	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/template"
+
+	"github.com/go-chi/chi/v5"
 )
 
 func (s *Server) handleListTemplates(w http.ResponseWriter, r *http.Request) {
@@ -17,19 +22,101 @@ func (s *Server) handleListTemplates(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleUpsertCSVTemplate(w http.ResponseWriter, r *http.Request) {
-	var t domain.BankTemplate
-	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+	raw, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	// documents without schemaVersion are the original format
+	t, _, err := template.Migrate(raw)
+	if err != nil {
 		http.Error(w, err.Error(), 400)
 		return
 	}
 	if t.Type == "" {
 		t.Type = "csv"
 	}
+	prev, _ := s.meta.GetTemplate(t.ID)
 	if err := s.meta.UpsertTemplate(t); err != nil {
+		var verr *template.ValidationError
+		if errors.As(err, &verr) {
+			writeJSON(w, map[string]any{"error": "invalid template", "fields": verr.Errors}, 400)
+			return
+		}
 		http.Error(w, err.Error(), 400)
 		return
 	}
-	writeJSON(w, map[string]any{"ok": true, "id": t.ID}, 200)
+	saved, err := s.meta.GetTemplate(t.ID)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	res := map[string]any{"ok": true, "id": t.ID, "revision": saved.Revision}
+	if prev != nil && prev.CSV.Timezone != t.CSV.Timezone && s.importedWith(t.ID, "") {
+		res["warning"] = tzChanged
+	}
+	writeJSON(w, res, 200)
+}
+
+// handleTemplateSchema serves the JSON Schema of the current template format.
+func (s *Server) handleTemplateSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", "application/schema+json")
+	_, _ = w.Write(template.Schema)
+}
+
+func (s *Server) handleGetTemplate(w http.ResponseWriter, r *http.Request) {
+	t, err := s.meta.GetTemplate(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	writeJSON(w, t, 200)
+}
+
+func (s *Server) handleDeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := s.meta.DeleteTemplate(chi.URLParam(r, "id")); err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true}, 200)
+}
+
+func (s *Server) handleListTemplateRevisions(w http.ResponseWriter, r *http.Request) {
+	list, err := s.meta.ListTemplateRevisions(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	writeJSON(w, list, 200)
+}
+
+func (s *Server) handleGetTemplateRevision(w http.ResponseWriter, r *http.Request) {
+	rev, err := strconv.Atoi(chi.URLParam(r, "rev"))
+	if err != nil {
+		http.Error(w, "invalid revision", 400)
+		return
+	}
+	t, err := s.meta.GetTemplateRevision(chi.URLParam(r, "id"), rev)
+	if err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	writeJSON(w, t, 200)
+}
+
+// handleRollbackTemplate makes ?revision= current again as a new revision.
+func (s *Server) handleRollbackTemplate(w http.ResponseWriter, r *http.Request) {
+	rev, err := strconv.Atoi(r.URL.Query().Get("revision"))
+	if err != nil || rev <= 0 {
+		http.Error(w, "invalid revision", 400)
+		return
+	}
+	t, err := s.meta.RollbackTemplate(chi.URLParam(r, "id"), rev)
+	if err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	writeJSON(w, map[string]any{"ok": true, "id": t.ID, "revision": t.Revision}, 200)
 }
 
 func writeJSON(w http.ResponseWriter, v any, status int) {
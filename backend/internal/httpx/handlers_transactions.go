@@ -0,0 +1,156 @@
+package httpx
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/influx"
+)
+
+const (
+	defaultTxPageSize = 100
+	maxTxPageSize     = 1000
+)
+
+type txItem struct {
+	Time time.Time `json:"time"` // point time; transactionTime when the template keeps it
+	domain.Transaction
+}
+
+type txPage struct {
+	Items      []txItem `json:"items"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+// handleListTransactions reads stored bookings. Filters: ?account_id= and
+// ?category= (repeatable or comma-separated), ?from= and ?to= (YYYY-MM-DD,
+// both inclusive), ?direction=in|out, ?status=booked|pending, ?min_amount=
+// and ?max_amount= (cents, absolute), ?payee= and ?memo= (substring,
+// case-insensitive). ?sort= is date, -date, amount or -amount; pages of
+// ?limit= rows continue with the returned nextCursor as ?cursor=.
+func (s *Server) handleListTransactions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f, err := s.txFilterFromQuery(q)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	limit := defaultTxPageSize
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxTxPageSize {
+			http.Error(w, "invalid limit (1-"+strconv.Itoa(maxTxPageSize)+")", 400)
+			return
+		}
+		limit = n
+	}
+	// one extra row tells whether there is a next page
+	f.Limit = limit + 1
+
+	if v := q.Get("cursor"); v != "" {
+		c, err := decodeCursor(v)
+		if err != nil {
+			http.Error(w, "invalid cursor", 400)
+			return
+		}
+		f.After = &c
+	}
+
+	recs, err := s.inflx.QueryTransactions(r.Context(), f)
+	if err != nil {
+		http.Error(w, "influx query failed: "+err.Error(), 500)
+		return
+	}
+
+	page := txPage{Items: []txItem{}}
+	if len(recs) > limit {
+		recs = recs[:limit]
+		page.NextCursor = encodeCursor(influx.CursorOf(recs[len(recs)-1]))
+	}
+	for _, rec := range recs {
+		page.Items = append(page.Items, txItem{Time: rec.Time, Transaction: rec.Tx})
+	}
+	writeJSON(w, page, 200)
+}
+
+// txFilterFromQuery parses the filters shared by listing and exports.
+func (s *Server) txFilterFromQuery(q url.Values) (influx.TxFilter, error) {
+	f := influx.TxFilter{
+		TenantID:    s.cfg.DefaultTenant,
+		AccountIDs:  listParam(q["account_id"]),
+		CategoryIDs: listParam(q["category"]),
+		Direction:   q.Get("direction"),
+		Payee:       strings.TrimSpace(q.Get("payee")),
+		Memo:        strings.TrimSpace(q.Get("memo")),
+		Status:      q.Get("status"),
+		Sort:        influx.TxSort(q.Get("sort")),
+	}
+	if f.Direction != "" && f.Direction != "in" && f.Direction != "out" {
+		return f, fmt.Errorf("invalid direction (want in or out)")
+	}
+	if f.Status != "" && f.Status != domain.TxStatusBooked && f.Status != domain.TxStatusPending {
+		return f, fmt.Errorf("invalid status (want booked or pending)")
+	}
+	if !f.Sort.Valid() {
+		return f, fmt.Errorf("invalid sort (want date, -date, amount or -amount)")
+	}
+	for name, dst := range map[string]*time.Time{"from": &f.From, "to": &f.To} {
+		if v := q.Get(name); v != "" {
+			d, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				return f, fmt.Errorf("invalid %s (want YYYY-MM-DD)", name)
+			}
+			*dst = d
+		}
+	}
+	if !f.To.IsZero() {
+		f.To = f.To.AddDate(0, 0, 1)
+	}
+	for name, dst := range map[string]**int64{"min_amount": &f.MinAbsCents, "max_amount": &f.MaxAbsCents} {
+		if v := q.Get(name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				return f, fmt.Errorf("invalid %s (want cents)", name)
+			}
+			*dst = &n
+		}
+	}
+	return f, nil
+}
+
+// listParam flattens repeated and comma-separated query values.
+func listParam(vals []string) []string {
+	var out []string
+	for _, v := range vals {
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				out = append(out, p)
+			}
+		}
+	}
+	return out
+}
+
+// cursors are opaque to clients: base64url of the last row's sort key
+func encodeCursor(c influx.TxCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (influx.TxCursor, error) {
+	var c influx.TxCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
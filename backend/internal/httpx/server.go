@@ -26,7 +26,6 @@ func NewServer(cfg config.Config, metaStore *meta.Store, inflx *influx.Client) *
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
 
 	s := &Server{Router: r, cfg: cfg, meta: metaStore, inflx: inflx}
 
@@ -35,11 +34,56 @@ func NewServer(cfg config.Config, metaStore *meta.Store, inflx *influx.Client) *
 		_, _ = w.Write([]byte("ok"))
 	})
 
-	r.Route("/api/v1", func(api chi.Router) {
+	r.Route("/api/v1", func(root chi.Router) {
+		// exports stream for as long as the result takes, so no request timeout
+		root.Get("/exports/transactions", s.handleExportTransactions)
+
+		api := root.With(middleware.Timeout(60 * time.Second))
 		api.Get("/templates", s.handleListTemplates)
 		api.Post("/templates/csv", s.handleUpsertCSVTemplate)
+		api.Get("/templates/schema", s.handleTemplateSchema)
+		api.Get("/templates/{id}", s.handleGetTemplate)
+		api.Delete("/templates/{id}", s.handleDeleteTemplate)
+		api.Get("/templates/{id}/revisions", s.handleListTemplateRevisions)
+		api.Get("/templates/{id}/revisions/{rev}", s.handleGetTemplateRevision)
+		api.Post("/templates/{id}/rollback", s.handleRollbackTemplate)
+
+		api.Get("/merchants/aliases", s.handleListMerchantAliases)
+		api.Post("/merchants/aliases", s.handleUpsertMerchantAlias)
+		api.Delete("/merchants/aliases/{match}", s.handleDeleteMerchantAlias)
+		api.Get("/merchants/normalize", s.handleNormalizeMerchant)
+
+		api.Get("/accounts", s.handleListAccounts)
+		api.Post("/accounts", s.handleUpsertAccount)
+		api.Delete("/accounts/{id}", s.handleDeleteAccount)
 
 		api.Post("/imports/csv", s.handleImportCSV)
+		api.Get("/imports", s.handleListImports)
+		api.Get("/imports/{id}", s.handleGetImport)
+		api.Get("/transactions", s.handleListTransactions)
+		api.Get("/exports/datev/settings", s.handleGetDatevSettings)
+		api.Post("/exports/datev/settings", s.handlePutDatevSettings)
+		api.Get("/search", s.handleSearch)
+		api.Post("/search/reindex", s.handleReindexSearch)
+
+		api.Get("/recurring", s.handleRecurring)
+		api.Get("/forecast", s.handleForecast)
+		api.Get("/anomalies", s.handleAnomalies)
+		api.Get("/reports", s.handleReport)
+
+		api.Get("/budgets", s.handleListBudgets)
+		api.Post("/budgets", s.handleUpsertBudget)
+		api.Get("/budgets/status", s.handleBudgetStatus)
+		api.Delete("/budgets/{id}", s.handleDeleteBudget)
+
+		api.Get("/alerts", s.handleListAlerts)
+		api.Get("/alerts/rules", s.handleListAlertRules)
+		api.Post("/alerts/rules", s.handleUpsertAlertRule)
+		api.Delete("/alerts/rules/{id}", s.handleDeleteAlertRule)
+		api.Get("/alerts/channels", s.handleListAlertChannels)
+		api.Post("/alerts/channels", s.handleUpsertAlertChannel)
+		api.Delete("/alerts/channels/{id}", s.handleDeleteAlertChannel)
+		api.Post("/alerts/channels/{id}/test", s.handleTestAlertChannel)
 	})
 
 	return s
@@ -0,0 +1,96 @@
+package camt
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/importer/memo"
+	"bankdash/backend/internal/util"
+)
+
+// AmountDetails is an entry's or transaction's <AmtDtls>.
+type AmountDetails struct {
+	Instructed   *AmountAndExchange `xml:"InstdAmt"`
+	Transaction  *AmountAndExchange `xml:"TxAmt"`
+	CounterValue *AmountAndExchange `xml:"CntrValAmt"`
+}
+
+type AmountAndExchange struct {
+	Amount   Amount    `xml:"Amt"`
+	Exchange *Exchange `xml:"CcyXchg"`
+}
+
+type Amount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type Exchange struct {
+	SourceCurrency string `xml:"SrcCcy"`
+	TargetCurrency string `xml:"TrgtCcy"`
+	UnitCurrency   string `xml:"UnitCcy"`
+	Rate           string `xml:"XchgRate"`
+}
+
+// ApplyAmountDetails fills the original amount, currency and FX rate of a
+// foreign-currency entry from its amount details. tx must have its booked
+// amount and currency set. The foreign usage fee is not part of <AmtDtls>
+// (banks put it in <Chrgs>), so FXFeeCents is left alone.
+func ApplyAmountDetails(tx *domain.Transaction, d AmountDetails) {
+	for _, a := range []*AmountAndExchange{d.Instructed, d.Transaction} {
+		if a == nil {
+			continue
+		}
+		cur := strings.ToUpper(strings.TrimSpace(a.Amount.Currency))
+		if cur == "" || cur == tx.Currency {
+			continue
+		}
+		v, err := util.ParseAmount(a.Amount.Value, "en", "")
+		if err != nil {
+			continue
+		}
+		memo.SetOriginal(tx, v, cur)
+		break
+	}
+	if tx.OriginalCurrency == "" {
+		return
+	}
+	for _, a := range []*AmountAndExchange{d.Instructed, d.Transaction, d.CounterValue} {
+		if a == nil || a.Exchange == nil {
+			continue
+		}
+		if r := rate(*a.Exchange, tx.Currency, tx.OriginalCurrency); r > 0 {
+			tx.FXRate = r
+			break
+		}
+	}
+	memo.DeriveRate(tx)
+}
+
+// rate returns original units per one unit of account currency, or 0 if x
+// does not convert between the two. The rate is quoted per unit currency,
+// which defaults to the source currency.
+func rate(x Exchange, account, original string) float64 {
+	src := strings.ToUpper(strings.TrimSpace(x.SourceCurrency))
+	trgt := strings.ToUpper(strings.TrimSpace(x.TargetCurrency))
+	if !(src == account && trgt == original) && !(src == original && trgt == account) {
+		return 0
+	}
+	r, err := strconv.ParseFloat(strings.TrimSpace(x.Rate), 64)
+	if err != nil || r <= 0 {
+		return 0
+	}
+	unit := strings.ToUpper(strings.TrimSpace(x.UnitCurrency))
+	if unit == "" {
+		unit = src
+	}
+	switch unit {
+	case account:
+		return r
+	case original:
+		return math.Round(1/r*1e6) / 1e6
+	}
+	return 0
+}
@@ -0,0 +1,42 @@
+package camt
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"bankdash/backend/internal/domain"
+)
+
+func TestApplyAmountDetails(t *testing.T) {
+	tests := []struct {
+		name     string
+		xml      string
+		cents    int64
+		currency string
+		rate     float64
+	}{
+		{"converted from the source currency", `<AmtDtls>
+			<InstdAmt><Amt Ccy="USD">45.00</Amt>
+				<CcyXchg><SrcCcy>USD</SrcCcy><TrgtCcy>EUR</TrgtCcy><XchgRate>0.92115</XchgRate></CcyXchg></InstdAmt>
+			<TxAmt><Amt Ccy="EUR">41.45</Amt></TxAmt></AmtDtls>`, -4500, "USD", 1.0856},
+		{"quoted per account currency", `<AmtDtls>
+			<InstdAmt><Amt Ccy="USD">45.00</Amt></InstdAmt>
+			<CntrValAmt><Amt Ccy="EUR">41.45</Amt>
+				<CcyXchg><SrcCcy>USD</SrcCcy><TrgtCcy>EUR</TrgtCcy><UnitCcy>EUR</UnitCcy><XchgRate>1.0856</XchgRate></CcyXchg></CntrValAmt></AmtDtls>`, -4500, "USD", 1.0856},
+		{"rate from the amounts", `<AmtDtls><InstdAmt><Amt Ccy="usd">45.00</Amt></InstdAmt></AmtDtls>`, -4500, "USD", 1.085645},
+		{"no minor unit", `<AmtDtls><TxAmt><Amt Ccy="JPY">6700</Amt></TxAmt></AmtDtls>`, -6700, "JPY", 161.640531},
+		{"account currency only", `<AmtDtls><InstdAmt><Amt Ccy="EUR">41.45</Amt></InstdAmt><TxAmt><Amt Ccy="EUR">41.45</Amt></TxAmt></AmtDtls>`, 0, "", 0},
+	}
+	for _, tt := range tests {
+		var d AmountDetails
+		if err := xml.Unmarshal([]byte(tt.xml), &d); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		tx := domain.Transaction{AmountCents: -4145, Currency: "EUR"}
+		ApplyAmountDetails(&tx, d)
+		if tx.OriginalAmountCents != tt.cents || tx.OriginalCurrency != tt.currency || tx.FXRate != tt.rate {
+			t.Errorf("%s: original %d %q at %v, want %d %q at %v", tt.name,
+				tx.OriginalAmountCents, tx.OriginalCurrency, tx.FXRate, tt.cents, tt.currency, tt.rate)
+		}
+	}
+}
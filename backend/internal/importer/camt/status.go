@@ -0,0 +1,25 @@
+// Package camt maps ISO 20022 camt.052/camt.053 statement elements to
+// domain fields. The statement importer that reads the XML is still to
+// come; its entry decoding will call these.
+package camt
+
+import (
+	"strings"
+
+	"bankdash/backend/internal/domain"
+)
+
+// Status maps an entry's <Sts> code (camt.053.001.02 has it inline, later
+// versions as <Sts><Cd>) to a transaction status. Informational and future
+// entries are not on the books yet, so they count as pending and are
+// replaced once the booked entry arrives. It reports false for an unknown
+// code.
+func Status(code string) (string, bool) {
+	switch strings.ToUpper(strings.TrimSpace(code)) {
+	case "BOOK":
+		return domain.TxStatusBooked, true
+	case "PDNG", "INFO", "FUTR":
+		return domain.TxStatusPending, true
+	}
+	return "", false
+}
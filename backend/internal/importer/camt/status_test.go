@@ -0,0 +1,29 @@
+package camt
+
+import (
+	"testing"
+
+	"bankdash/backend/internal/domain"
+)
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+		ok   bool
+	}{
+		{"BOOK", domain.TxStatusBooked, true},
+		{" book ", domain.TxStatusBooked, true},
+		{"PDNG", domain.TxStatusPending, true},
+		{"INFO", domain.TxStatusPending, true},
+		{"FUTR", domain.TxStatusPending, true},
+		{"", "", false},
+		{"RJCT", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Status(tt.code)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Status(%q) = %q, %v, want %q, %v", tt.code, got, ok, tt.want, tt.ok)
+		}
+	}
+}
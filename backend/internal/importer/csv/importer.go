@@ -10,6 +10,9 @@ import (
 	"time"
 
 	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/importer/memo"
+	"bankdash/backend/internal/importer/transform"
+	"bankdash/backend/internal/merchant"
 	"bankdash/backend/internal/util"
 )
 
@@ -18,12 +21,24 @@ type Result struct {
 }
 
 type Importer struct {
-	loc *time.Location
+	loc       *time.Location
+	merchants *merchant.Normalizer
 }
 
-func New() *Importer {
-	loc, _ := time.LoadLocation("Europe/Berlin")
-	return &Importer{loc: loc}
+// New returns an importer placing dates and card purchase times in loc;
+// nil means UTC.
+func New(loc *time.Location) *Importer {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &Importer{loc: loc, merchants: merchant.NewNormalizer(nil)}
+}
+
+// WithMerchants replaces the default (builtin-only) merchant normalizer,
+// typically with one that also knows the user's alias table.
+func (i *Importer) WithMerchants(n *merchant.Normalizer) *Importer {
+	i.merchants = n
+	return i
 }
 
 func (i *Importer) Import(ctx context.Context, r io.Reader, tmpl domain.BankTemplate, tenantID, accountID, bankID string) ([]domain.Transaction, error) {
@@ -36,47 +51,84 @@ func (i *Importer) Import(ctx context.Context, r io.Reader, tmpl domain.BankTemp
 		return nil, err
 	}
 
+	transforms, err := transform.CompileAll(tmpl.CSV.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	// dates without a year are placed relative to the statement's last date
+	var yearRef time.Time
+	for _, f := range tmpl.CSV.DateFormats {
+		if !util.LayoutHasYear(f) {
+			yearRef = i.statementEnd(rows, tmpl, transforms)
+			break
+		}
+	}
+
 	var out []domain.Transaction
 	for _, row := range rows {
-		tx, err := i.rowToTx(row, tmpl, tenantID, accountID, bankID)
+		tx, err := i.rowToTx(row, tmpl, transforms, yearRef, tenantID, accountID, bankID)
 		if err != nil {
 			// MVP: fail-fast. Later: collect row errors with line numbers.
 			return nil, err
 		}
+		i.normalize(&tx)
 		out = append(out, tx)
 	}
 	return out, nil
 }
 
-func (i *Importer) rowToTx(row map[string]string, tmpl domain.BankTemplate, tenantID, accountID, bankID string) (domain.Transaction, error) {
-	c := tmpl.CSV.Columns
+func (i *Importer) rowToTx(row map[string]string, tmpl domain.BankTemplate, transforms transform.Set, yearRef time.Time, tenantID, accountID, bankID string) (domain.Transaction, error) {
+	raw := cells(row, tmpl.CSV.Columns)
+	v := transforms.Apply(raw)
 
-	bookingDate, err := util.ParseDate(row[c.BookingDate], tmpl.CSV.DateFormats, i.loc)
+	booking, err := i.parseDate(v["bookingDate"], tmpl.CSV, yearRef)
 	if err != nil {
 		return domain.Transaction{}, fmt.Errorf("bookingDate parse: %w", err)
 	}
+	bookingDate := booking.Date
+	var txTime *time.Time
+	if tmpl.CSV.KeepTime {
+		txTime = booking.Time
+	}
 
 	var valueDate *time.Time
-	if c.ValueDate != "" {
-		if v := row[c.ValueDate]; v != "" {
-			d, err := util.ParseDate(v, tmpl.CSV.DateFormats, i.loc)
-			if err != nil {
-				return domain.Transaction{}, fmt.Errorf("valueDate parse: %w", err)
-			}
-			valueDate = &d
+	if s := v["valueDate"]; s != "" {
+		d, err := i.parseDate(s, tmpl.CSV, yearRef)
+		if err != nil {
+			return domain.Transaction{}, fmt.Errorf("valueDate parse: %w", err)
 		}
+		valueDate = &d.Date
 	}
 
-	amountCents, err := util.ParseAmountCents(row[c.Amount], tmpl.CSV.Decimal, tmpl.CSV.ThousandsSep)
-	if err != nil {
-		return domain.Transaction{}, fmt.Errorf("amount parse: %w", err)
+	// a currency column wins, then the template's; a symbol or code in the
+	// amount cell only fills in when neither says
+	currency := v["currency"]
+	if currency == "" {
+		currency = tmpl.CSV.Currency
+	}
+	if currency == "" {
+		currency = util.AmountCurrency(v["amount"])
 	}
-
-	currency := row[c.Currency]
 	if currency == "" {
 		currency = "EUR"
 	}
 
+	// amounts are minor units of the currency (cents, or yen for JPY); the
+	// exact figure is kept when the file has more digits than that
+	amount, err := util.ParseAmount(v["amount"], tmpl.CSV.Decimal, tmpl.CSV.ThousandsSep)
+	if err != nil {
+		return domain.Transaction{}, fmt.Errorf("amount parse: %w", err)
+	}
+	amountCents, err := amount.Minor(util.MinorUnits(currency), util.RoundingMode(tmpl.CSV.Rounding))
+	if err != nil {
+		return domain.Transaction{}, fmt.Errorf("amount parse: %w", err)
+	}
+	amountDecimal := ""
+	if !amount.Exact(util.MinorUnits(currency)) {
+		amountDecimal = amount.String()
+	}
+
 	direction := "out"
 	if amountCents >= 0 {
 		direction = "in"
@@ -84,8 +136,135 @@ func (i *Importer) rowToTx(row map[string]string, tmpl domain.BankTemplate, tena
 		// store absolute value as negative? We keep signed for now (better for debugging)
 	}
 
-	payee := row[c.Payee]
+	payee := v["payee"]
+	memo := v["memo"]
+	ref := v["reference"]
+	iban := v["iban"]
+
+	status := domain.TxStatusBooked
+	if isPending(v["status"], tmpl.CSV.PendingValues) {
+		status = domain.TxStatusPending
+	}
+
+	var balance *int64
+	if s := v["balance"]; s != "" {
+		b, err := util.ParseAmountMinor(s, tmpl.CSV.Decimal, tmpl.CSV.ThousandsSep, currency, util.RoundingMode(tmpl.CSV.Rounding))
+		if err != nil {
+			return domain.Transaction{}, fmt.Errorf("balance parse: %w", err)
+		}
+		balance = &b
+	}
 
+	// stable UID (used for deterministic timestamp to make re-import idempotent)
+	// hashes the untransformed text, so editing a template's transforms
+	// does not duplicate bookings on re-import
+	rawCurrency := raw["currency"]
+	if rawCurrency == "" {
+		// the currency found in the amount cell, so "45 USD" and "45 GBP"
+		// stay two bookings; otherwise the template's or EUR, as before
+		rawCurrency = currency
+	}
+	// and the amount as it was stored before minor units followed the
+	// currency (cents, extra digits cut off), so JPY or 4-digit amounts
+	// keep the uid of their first import
+	uidCents, err := amount.Minor(2, util.RoundDown)
+	if err != nil {
+		return domain.Transaction{}, fmt.Errorf("amount parse: %w", err)
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%s|%s|%s|%s",
+		accountID,
+		bookingDate.Format("2006-01-02"),
+		uidCents,
+		rawCurrency,
+		raw["payee"],
+		raw["memo"],
+		raw["reference"],
+	)))
+	txUID := hex.EncodeToString(sum[:])
+
+	return domain.Transaction{
+		TenantID:        tenantID,
+		AccountID:       accountID,
+		BankID:          bankID,
+		BookingDate:     bookingDate,
+		ValueDate:       valueDate,
+		TransactionTime: txTime,
+		AmountCents:     amountCents,
+		AmountDecimal:   amountDecimal,
+		Currency:        currency,
+		Direction:       direction,
+		Status:          status,
+		BalanceCents:    balance,
+		Payee:           payee,
+		Memo:            memo,
+		Reference:       ref,
+		IBAN:            iban,
+		CategoryID:      domain.CategoryUncategorized,
+		TxUID:           txUID,
+	}, nil
+}
+
+func (i *Importer) dateOptions(cfg domain.CSVTemplate) util.DateOptions {
+	return util.DateOptions{Formats: cfg.DateFormats, Loc: i.loc, YearPivot: cfg.YearPivot}
+}
+
+// parseDate completes dates without a year from yearRef.
+func (i *Importer) parseDate(s string, cfg domain.CSVTemplate, yearRef time.Time) (util.ParsedDate, error) {
+	p, err := util.ParseDate(s, i.dateOptions(cfg))
+	if err != nil || p.HasYear {
+		return p, err
+	}
+	p.Date = util.InferYear(p.Date, yearRef)
+	if p.Time != nil {
+		t := util.InferYear(*p.Time, yearRef)
+		p.Time = &t
+	}
+	p.HasYear = true
+	return p, nil
+}
+
+// statementEnd is the latest dated booking or value date in rows, or now
+// when no row names a year.
+func (i *Importer) statementEnd(rows []map[string]string, tmpl domain.BankTemplate, transforms transform.Set) time.Time {
+	var end time.Time
+	for _, row := range rows {
+		v := transforms.Apply(cells(row, tmpl.CSV.Columns))
+		for _, s := range []string{v["bookingDate"], v["valueDate"]} {
+			if p, err := util.ParseDate(s, i.dateOptions(tmpl.CSV)); err == nil && p.HasYear && p.Date.After(end) {
+				end = p.Date
+			}
+		}
+	}
+	if end.IsZero() {
+		end = time.Now().In(i.loc)
+	}
+	return end
+}
+
+func isPending(cell string, values []string) bool {
+	cell = strings.TrimSpace(cell)
+	if cell == "" {
+		return false
+	}
+	if len(values) == 0 {
+		values = domain.DefaultPendingValues
+	}
+	for _, v := range values {
+		if strings.EqualFold(cell, strings.TrimSpace(v)) {
+			return true
+		}
+	}
+	return false
+}
+
+// cells reads the value of every mapped field from row, before transforms.
+func cells(row map[string]string, c domain.CSVColumns) map[string]string {
+	col := func(name string) string {
+		if name == "" {
+			return ""
+		}
+		return row[name]
+	}
 	// NEW: compose memo
 	memo := ""
 	if len(c.MemoFields) > 0 {
@@ -97,44 +276,29 @@ func (i *Importer) rowToTx(row map[string]string, tmpl domain.BankTemplate, tena
 		}
 		memo = strings.Join(parts, " | ")
 	} else {
-		memo = row[c.Memo]
+		memo = col(c.Memo)
 	}
-	
-	ref := ""
-	if c.Reference != "" {
-		ref = row[c.Reference]
+	return map[string]string{
+		"bookingDate": col(c.BookingDate),
+		"valueDate":   col(c.ValueDate),
+		"amount":      col(c.Amount),
+		"currency":    col(c.Currency),
+		"payee":       col(c.Payee),
+		"memo":        memo,
+		"reference":   col(c.Reference),
+		"iban":        col(c.Iban),
+		"balance":     col(c.Balance),
+		"status":      col(c.Status),
 	}
-	iban := ""
-	if c.Iban != "" {
-		iban = row[c.Iban]
-	}
-
-	// stable UID (used for deterministic timestamp to make re-import idempotent)
-	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%s|%s|%s|%s",
-		accountID,
-		bookingDate.Format("2006-01-02"),
-		amountCents,
-		currency,
-		payee,
-		memo,
-		ref,
-	)))
-	txUID := hex.EncodeToString(sum[:])
+}
 
-	return domain.Transaction{
-		TenantID:    tenantID,
-		AccountID:   accountID,
-		BankID:      bankID,
-		BookingDate: bookingDate,
-		ValueDate:   valueDate,
-		AmountCents: amountCents,
-		Currency:    currency,
-		Direction:   direction,
-		Payee:       payee,
-		Memo:        memo,
-		Reference:   ref,
-		IBAN:        iban,
-		CategoryID:  "uncategorized",
-		TxUID:       txUID,
-	}, nil
+// normalize runs after rowToTx; it only derives fields and never touches the
+// inputs of TxUID, so re-imports keep their uid when aliases change. The
+// merchant is a tag, so the import handler deletes the point written under
+// the old one.
+func (i *Importer) normalize(tx *domain.Transaction) {
+	memo.Apply(tx, i.loc)
+	if i.merchants != nil {
+		tx.Merchant = i.merchants.Normalize(tx.Payee)
+	}
 }
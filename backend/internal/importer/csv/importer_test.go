@@ -0,0 +1,40 @@
+package csvimporter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"bankdash/backend/internal/domain"
+)
+
+func TestTxUIDCurrencyFromAmountCell(t *testing.T) {
+	tmpl := domain.BankTemplate{ID: "wallet", Type: "csv", CSV: domain.CSVTemplate{
+		Delimiter:   ";",
+		HasHeader:   true,
+		DateFormats: []string{"02.01.2006"},
+		Decimal:     "de",
+		Columns:     domain.CSVColumns{BookingDate: "Datum", Amount: "Betrag", Payee: "Empfänger"},
+	}}
+	const file = `Datum;Betrag;Empfänger
+02.01.2026;-45,00 USD;Hotel
+02.01.2026;-45,00 GBP;Hotel
+02.01.2026;-45,00 €;Hotel
+02.01.2026;-45,00;Hotel
+`
+	txs, err := New(time.UTC).Import(context.Background(), strings.NewReader(file), tmpl, "default", "main", "bank")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txs[0].Currency != "USD" || txs[1].Currency != "GBP" {
+		t.Fatalf("currencies %q, %q", txs[0].Currency, txs[1].Currency)
+	}
+	if txs[0].TxUID == txs[1].TxUID {
+		t.Errorf("USD and GBP bookings share tx_uid %s", txs[0].TxUID)
+	}
+	// without a code the template's default (EUR) is hashed, as before
+	if txs[2].TxUID != txs[3].TxUID {
+		t.Errorf("€ and plain EUR amount got different tx_uids")
+	}
+}
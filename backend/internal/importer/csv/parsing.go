@@ -6,10 +6,15 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"unicode/utf8"
 
 	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/importer/rowfilter"
 )
 
+// ParseCSV returns the data rows of r keyed by header (or col_N). Rows the
+// template's include/exclude predicates reject are dropped, and reading stops
+// at the first row matching an endOfData predicate.
 func ParseCSV(r io.Reader, cfg domain.CSVTemplate) ([]map[string]string, error) {
 	br := bufio.NewReader(r)
 
@@ -20,13 +25,18 @@ func ParseCSV(r io.Reader, cfg domain.CSVTemplate) ([]map[string]string, error)
 		case "\\t":
 			del = '\t'
 		default:
-			if len(cfg.Delimiter) != 1 {
+			if utf8.RuneCountInString(cfg.Delimiter) != 1 {
 				return nil, fmt.Errorf("invalid delimiter: %q", cfg.Delimiter)
 			}
-			del = rune(cfg.Delimiter[0])
+			del, _ = utf8.DecodeRuneInString(cfg.Delimiter)
 		}
 	}
 
+	filter, err := rowfilter.Compile(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	cr := csv.NewReader(br)
 	cr.Comma = del
 	cr.FieldsPerRecord = -1
@@ -92,7 +102,12 @@ func ParseCSV(r io.Reader, cfg domain.CSVTemplate) ([]map[string]string, error)
 				row[fmt.Sprintf("col_%d", idx)] = cleanCell(rec[idx])
 			}
 		}
-		out = append(out, row)
+		if filter.End(row) {
+			break
+		}
+		if filter.Keep(row) {
+			out = append(out, row)
+		}
 	}
 
 	return out, nil
@@ -114,6 +129,8 @@ func requiredHeaders(cfg domain.CSVTemplate) []string {
 	add(c.Currency)
 	add(c.Reference)
 	add(c.Iban)
+	add(c.Balance)
+	add(c.Status)
 
 	if len(c.MemoFields) > 0 {
 		for _, m := range c.MemoFields {
@@ -0,0 +1,103 @@
+package csvimporter
+
+import (
+	"strings"
+	"testing"
+
+	"bankdash/backend/internal/domain"
+)
+
+const filterCSV = `Buchung;Valuta;Auftraggeber/Empfänger;Betrag;Status
+02.01.2026;02.01.2026;REWE;-28,95;Gebucht
+03.01.2026;;Shell;-60,00;Vorgemerkt
+;;Übertrag;;
+04.01.2026;04.01.2026;Gehalt;2.500,00;Gebucht
+
+Kontostand am 04.01.2026;;;1.234,56;
+05.01.2026;05.01.2026;nach dem Kontostand;-1,00;Gebucht
+`
+
+func TestParseCSVRowFilters(t *testing.T) {
+	const payee = "Auftraggeber/Empfänger"
+	tests := []struct {
+		name      string
+		include   []domain.RowPredicate
+		exclude   []domain.RowPredicate
+		endOfData []domain.RowPredicate
+		want      []string // payees, or Buchung for rows without one
+	}{
+		{name: "no filters",
+			want: []string{"REWE", "Shell", "Übertrag", "Gehalt", "Kontostand am 04.01.2026", "nach dem Kontostand"}},
+		{name: "exclude pending",
+			exclude: []domain.RowPredicate{{Column: "Status", Equals: "Vorgemerkt"}},
+			want:    []string{"REWE", "Übertrag", "Gehalt", "Kontostand am 04.01.2026", "nach dem Kontostand"}},
+		{name: "exclude ignoring case",
+			exclude: []domain.RowPredicate{{Column: "Status", Equals: "vorgemerkt", IgnoreCase: true}},
+			want:    []string{"REWE", "Übertrag", "Gehalt", "Kontostand am 04.01.2026", "nach dem Kontostand"}},
+		{name: "equals is case sensitive",
+			exclude: []domain.RowPredicate{{Column: "Status", Equals: "vorgemerkt"}},
+			want:    []string{"REWE", "Shell", "Übertrag", "Gehalt", "Kontostand am 04.01.2026", "nach dem Kontostand"}},
+		{name: "include booked",
+			include: []domain.RowPredicate{{Column: "Status", Equals: "Gebucht"}},
+			want:    []string{"REWE", "Gehalt", "nach dem Kontostand"}},
+		{name: "exclude empty date",
+			exclude: []domain.RowPredicate{{Column: "Buchung", Empty: true}},
+			want:    []string{"REWE", "Shell", "Gehalt", "Kontostand am 04.01.2026", "nach dem Kontostand"}},
+		{name: "a missing column is empty",
+			exclude: []domain.RowPredicate{{Column: "Notiz", Empty: true}},
+			want:    nil},
+		{name: "any cell",
+			exclude: []domain.RowPredicate{{Contains: "shell", IgnoreCase: true}},
+			want:    []string{"REWE", "Übertrag", "Gehalt", "Kontostand am 04.01.2026", "nach dem Kontostand"}},
+		{name: "end at the footer",
+			endOfData: []domain.RowPredicate{{Pattern: "^kontostand", IgnoreCase: true}},
+			want:      []string{"REWE", "Shell", "Übertrag", "Gehalt"}},
+		{name: "all conditions of a predicate must hold",
+			endOfData: []domain.RowPredicate{{Column: "Buchung", Contains: "Kontostand", Pattern: `05\.01`}},
+			want:      []string{"REWE", "Shell", "Übertrag", "Gehalt", "Kontostand am 04.01.2026", "nach dem Kontostand"}},
+		{name: "README example",
+			exclude:   []domain.RowPredicate{{Column: "Status", Equals: "Vorgemerkt"}, {Column: "Buchung", Empty: true}},
+			endOfData: []domain.RowPredicate{{Pattern: "^Kontostand", IgnoreCase: true}},
+			want:      []string{"REWE", "Gehalt"}},
+	}
+	for _, tt := range tests {
+		cfg := domain.CSVTemplate{Delimiter: ";", HasHeader: true,
+			Include: tt.include, Exclude: tt.exclude, EndOfData: tt.endOfData}
+		rows, err := ParseCSV(strings.NewReader(filterCSV), cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, row := range rows {
+			if row[payee] != "" {
+				got = append(got, row[payee])
+			} else {
+				got = append(got, row["Buchung"])
+			}
+		}
+		if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+			t.Errorf("%s: rows %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseCSVRowFiltersWithoutHeader(t *testing.T) {
+	cfg := domain.CSVTemplate{Delimiter: ";", SkipRows: 1,
+		Exclude:   []domain.RowPredicate{{Column: "col_4", Equals: "Vorgemerkt"}},
+		EndOfData: []domain.RowPredicate{{Column: "col_0", Contains: "Kontostand"}}}
+	rows, err := ParseCSV(strings.NewReader(filterCSV), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0]["col_2"] != "REWE" || rows[2]["col_2"] != "Gehalt" {
+		t.Fatalf("rows = %v", rows)
+	}
+}
+
+func TestParseCSVRejectsBadPredicate(t *testing.T) {
+	cfg := domain.CSVTemplate{Delimiter: ";", HasHeader: true,
+		Exclude: []domain.RowPredicate{{Column: "Status", Equals: "x"}, {Column: "Status"}}}
+	if _, err := ParseCSV(strings.NewReader(filterCSV), cfg); err == nil || !strings.HasPrefix(err.Error(), "exclude[1]:") {
+		t.Fatalf("ParseCSV() = %v, want an exclude[1] error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package memo
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/util"
+)
+
+// bank-agnostic wording for foreign-currency card payments, e.g.
+//
+//	... ORIGINAL 45,00 USD KURS 1,0856 AUSLANDSEINSATZENTGELT 0,73 EUR
+//	... Originalbetrag: 12.00 GBP, Wechselkurs: 0.8581, Fremdwährungsentgelt 0,25
+var (
+	fxOriginalRe = regexp.MustCompile(`(?i)\b(?:original(?:betrag)?|orig\.?)\s*:?\s*(-?[\d.,]+)\s*([A-Z]{3})\b`)
+	fxRateRe     = regexp.MustCompile(`(?i)\b(?:kurs|wechselkurs|umrechnungskurs|exchange rate|fx rate)\s*:?\s*([\d.,]+)`)
+	fxFeeRe      = regexp.MustCompile(`(?i)\b(?:auslandseinsatzentgelt|fremdw(?:ä|ae)hrungsentgelt|w(?:ä|ae)hrungsumrechnungsentgelt|fx fee|foreign transaction fee)\s*:?\s*([\d.,]+)(\s*%)?`)
+)
+
+// parseFX fills the original amount/currency, rate and fee from free-text
+// wording. Bank parsers may have set some of them already; those win.
+func parseFX(tx *domain.Transaction) {
+	if tx.OriginalCurrency == "" {
+		if m := fxOriginalRe.FindStringSubmatch(tx.Memo); m != nil {
+			cur := strings.ToUpper(m[2])
+			if cur != tx.Currency {
+				if v, err := parseMemoAmount(m[1]); err == nil {
+					SetOriginal(tx, v, cur)
+				}
+			}
+		}
+	}
+	if tx.FXRate == 0 {
+		if m := fxRateRe.FindStringSubmatch(tx.Memo); m != nil {
+			tx.FXRate = parseRate(m[1])
+		}
+	}
+	if tx.FXFeeCents == 0 {
+		if m := fxFeeRe.FindStringSubmatch(tx.Memo); m != nil && m[2] == "" {
+			if v, err := parseMemoAmount(m[1]); err == nil {
+				tx.FXFeeCents = feeMinor(v, tx.Currency)
+			}
+		}
+	}
+	DeriveRate(tx)
+}
+
+// DeriveRate sets a missing FX rate from the original and booked amounts.
+func DeriveRate(tx *domain.Transaction) {
+	if tx.FXRate == 0 && tx.OriginalAmountCents != 0 && tx.AmountCents != 0 {
+		// original units per one unit of account currency
+		r := float64(tx.OriginalAmountCents) / float64(tx.AmountCents) *
+			math.Pow10(util.MinorUnits(tx.Currency)-util.MinorUnits(tx.OriginalCurrency))
+		tx.FXRate = math.Round(math.Abs(r)*1e6) / 1e6
+	}
+}
+
+// SetOriginal stores the original amount, in minor units of currency, with
+// the sign of the booked amount. Structured formats use it as well.
+func SetOriginal(tx *domain.Transaction, d util.Decimal, currency string) {
+	exp := util.MinorUnits(currency)
+	minor, err := d.Minor(exp, util.RoundHalfUp)
+	if err != nil {
+		return
+	}
+	exact := strings.TrimPrefix(d.String(), "-")
+	if minor < 0 {
+		minor = -minor
+	}
+	if tx.AmountCents < 0 {
+		minor = -minor
+		exact = "-" + exact
+	}
+	tx.OriginalAmountCents = minor
+	tx.OriginalCurrency = currency
+	if !d.Exact(exp) {
+		tx.OriginalAmountDecimal = exact
+	}
+}
+
+// feeMinor converts a fee to minor units of the account currency, positive.
+func feeMinor(d util.Decimal, currency string) int64 {
+	minor, err := d.Minor(util.MinorUnits(currency), util.RoundHalfUp)
+	if err != nil {
+		return 0
+	}
+	if minor < 0 {
+		return -minor
+	}
+	return minor
+}
+
+// parseMemoAmount accepts both "28.95" (card memos) and "28,95" (German text).
+func parseMemoAmount(s string) (util.Decimal, error) {
+	dec, sep := "en", ","
+	if strings.LastIndexByte(s, ',') > strings.LastIndexByte(s, '.') {
+		dec, sep = "de", "."
+	}
+	return util.ParseAmount(s, dec, sep)
+}
+
+func parseRate(s string) float64 {
+	if strings.Contains(s, ",") && !strings.Contains(s, ".") {
+		s = strings.ReplaceAll(s, ",", ".")
+	}
+	s = strings.TrimRight(s, ".")
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v <= 0 {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,65 @@
+package memo
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"bankdash/backend/internal/domain"
+)
+
+// ING Visa debit lines, e.g.
+//
+//	NR XXXX 7029 BRAUNSCHWEI DE KAUFUMSATZ 23.12 28.95 171259 ARN74830725357313108906693
+//	NR XXXX 7011 BS-ILLERST DE BARGELDAUSZAHLUNG 20.11 150.00 150146 ARN74049295325001069546281
+//	NR XXXX 7029 NEW YORK US KURS 1,0856 KAUFUMSATZ 12.08 54.27 USD 175959 ARN...
+//
+// card number tail, merchant city (truncated by the bank), country, optional
+// FX rate, kind, purchase day.month, amount in purchase currency (+ code when
+// foreign), purchase time hhmmss, acquirer reference number.
+var ingCardRe = regexp.MustCompile(
+	`NR X+ ?(\d{4}) (.+?) ([A-Z]{2})(?: KURS ([\d.,]+))? (KAUFUMSATZ|BARGELDAUSZAHLUNG) (\d{2})\.(\d{2}) ([\d.,]+)(?: ([A-Z]{3}))? (\d{2})(\d{2})(\d{2})(?: ARN(\d+))?`,
+)
+
+// ING appends the foreign usage fee as a separate phrase after the ARN.
+var ingFeeRe = regexp.MustCompile(`AUSLANDSEINSATZENTGELT ([\d.,]+)`)
+
+func parseINGCard(tx *domain.Transaction, loc *time.Location) bool {
+	m := ingCardRe.FindStringSubmatch(tx.Memo)
+	if m == nil {
+		return false
+	}
+
+	tx.CardLast4 = m[1]
+	tx.MerchantCity = m[2]
+	tx.MerchantCountry = m[3]
+	tx.ARN = m[13]
+
+	if cur := m[9]; cur != "" && cur != tx.Currency {
+		if v, err := parseMemoAmount(m[8]); err == nil {
+			SetOriginal(tx, v, cur)
+		}
+		tx.FXRate = parseRate(m[4])
+	}
+	if f := ingFeeRe.FindStringSubmatch(tx.Memo); f != nil {
+		if v, err := parseMemoAmount(f[1]); err == nil {
+			tx.FXFeeCents = feeMinor(v, tx.Currency)
+		}
+	}
+
+	day, _ := strconv.Atoi(m[6])
+	month, _ := strconv.Atoi(m[7])
+	hh, _ := strconv.Atoi(m[10])
+	mm, _ := strconv.Atoi(m[11])
+	ss, _ := strconv.Atoi(m[12])
+	if hh > 23 || mm > 59 || ss > 59 {
+		hh, mm, ss = 0, 0, 0
+	}
+	if loc == nil {
+		loc = tx.BookingDate.Location()
+	}
+	if t, ok := inferYear(day, month, tx.BookingDate, hh, mm, ss, loc); ok {
+		tx.PurchaseDate = &t
+	}
+	return true
+}
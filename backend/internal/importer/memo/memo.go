@@ -0,0 +1,46 @@
+package memo
+
+import (
+	"time"
+
+	"bankdash/backend/internal/domain"
+)
+
+// Parser extracts structured details from a transaction's memo into the
+// transaction itself. It reports false if the memo is not in its format.
+type Parser func(tx *domain.Transaction, loc *time.Location) bool
+
+// parsers are tried in order; the first one that matches wins.
+var parsers = []Parser{
+	parseINGCard,
+}
+
+// Apply runs the known bank memo parsers against tx.
+func Apply(tx *domain.Transaction, loc *time.Location) {
+	if tx.Memo == "" {
+		return
+	}
+	for _, p := range parsers {
+		if p(tx, loc) {
+			break
+		}
+	}
+	parseFX(tx)
+}
+
+// inferYear places a day/month without year at or before ref
+// (card purchases are booked after they happen, sometimes across New Year).
+func inferYear(day, month int, ref time.Time, hh, mm, ss int, loc *time.Location) (time.Time, bool) {
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, false
+	}
+	t := time.Date(ref.Year(), time.Month(month), day, hh, mm, ss, 0, loc)
+	if t.Day() != day {
+		// e.g. 29.02 in a non-leap year
+		return time.Time{}, false
+	}
+	if t.After(ref.AddDate(0, 0, 1)) {
+		t = time.Date(ref.Year()-1, time.Month(month), day, hh, mm, ss, 0, loc)
+	}
+	return t, true
+}
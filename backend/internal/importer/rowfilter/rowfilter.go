@@ -0,0 +1,128 @@
+// Package rowfilter evaluates a template's row predicates against raw CSV
+// rows, before any field is parsed.
+package rowfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"bankdash/backend/internal/domain"
+)
+
+type predicate struct {
+	domain.RowPredicate
+	re *regexp.Regexp
+}
+
+// Filter is the compiled include, exclude and end-of-data predicates of a
+// template.
+type Filter struct {
+	include, exclude, end []predicate
+}
+
+// Compile prepares the predicates of cfg. Errors name the list and index,
+// e.g. "exclude[1]".
+func Compile(cfg domain.CSVTemplate) (*Filter, error) {
+	f := &Filter{}
+	for _, l := range []struct {
+		name string
+		in   []domain.RowPredicate
+		out  *[]predicate
+	}{
+		{"include", cfg.Include, &f.include},
+		{"exclude", cfg.Exclude, &f.exclude},
+		{"endOfData", cfg.EndOfData, &f.end},
+	} {
+		for i, p := range l.in {
+			c, err := compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("%s[%d]: %w", l.name, i, err)
+			}
+			*l.out = append(*l.out, c)
+		}
+	}
+	return f, nil
+}
+
+// Check reports why p cannot be compiled, or nil.
+func Check(p domain.RowPredicate) error {
+	_, err := compile(p)
+	return err
+}
+
+func compile(p domain.RowPredicate) (predicate, error) {
+	c := predicate{RowPredicate: p}
+	if p.Equals == "" && p.Contains == "" && p.Pattern == "" && !p.Empty {
+		return c, fmt.Errorf("needs equals, contains, pattern or empty")
+	}
+	if p.Pattern != "" {
+		pat := p.Pattern
+		if p.IgnoreCase {
+			pat = "(?i)" + pat
+		}
+		re, err := regexp.Compile(pat)
+		if err != nil {
+			return c, fmt.Errorf("invalid pattern: %v", err)
+		}
+		c.re = re
+	}
+	return c, nil
+}
+
+// Keep reports whether row passes the include and exclude predicates.
+func (f *Filter) Keep(row map[string]string) bool {
+	for _, p := range f.include {
+		if !p.matchRow(row) {
+			return false
+		}
+	}
+	for _, p := range f.exclude {
+		if p.matchRow(row) {
+			return false
+		}
+	}
+	return true
+}
+
+// End reports whether row marks the end of the data.
+func (f *Filter) End(row map[string]string) bool {
+	for _, p := range f.end {
+		if p.matchRow(row) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p predicate) matchRow(row map[string]string) bool {
+	if p.Column != "" {
+		return p.match(row[p.Column])
+	}
+	for _, v := range row {
+		if p.match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p predicate) match(v string) bool {
+	v = strings.TrimSpace(v)
+	if p.Empty && v != "" {
+		return false
+	}
+	if p.Equals != "" && !(v == p.Equals || p.IgnoreCase && strings.EqualFold(v, p.Equals)) {
+		return false
+	}
+	if p.Contains != "" {
+		hay, needle := v, p.Contains
+		if p.IgnoreCase {
+			hay, needle = strings.ToLower(hay), strings.ToLower(needle)
+		}
+		if !strings.Contains(hay, needle) {
+			return false
+		}
+	}
+	return p.re == nil || p.re.MatchString(v)
+}
@@ -0,0 +1,247 @@
+// Package transform runs the declarative field transforms of a template on
+// CSV cells before they are parsed.
+package transform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"bankdash/backend/internal/domain"
+)
+
+const (
+	OpRegexExtract       = "regexExtract"       // first match (or Group) of Pattern; Default or "" without one
+	OpRegexReplace       = "regexReplace"       // every match of Pattern -> Replace
+	OpTrim               = "trim"               // Chars from both ends, whitespace by default
+	OpLower              = "lower"              //
+	OpUpper              = "upper"              //
+	OpCollapseWhitespace = "collapseWhitespace" // runs of whitespace -> one space, trimmed
+	OpStripPrefix        = "stripPrefix"        // the first of Values the value starts with
+	OpStripSuffix        = "stripSuffix"        // the first of Values the value ends with
+	OpSplit              = "split"              // part Index of the value split at Sep; Default or "" if missing
+	OpMap                = "map"                // Map[value]; Default, else the value unchanged, if not mapped
+)
+
+// Fields are the template columns a chain can be declared for, in the order
+// chains run. From always reads the untransformed cell, so the order does
+// not change results.
+var Fields = []string{
+	"bookingDate", "valueDate", "amount", "currency", "payee", "memo", "reference", "iban", "balance", "status",
+}
+
+// StepError points at the failing step of a chain.
+type StepError struct {
+	Index   int
+	Message string
+}
+
+func (e *StepError) Error() string { return fmt.Sprintf("[%d]: %s", e.Index, e.Message) }
+
+func stepErr(i int, format string, args ...any) error {
+	return &StepError{Index: i, Message: fmt.Sprintf(format, args...)}
+}
+
+// Chain is a compiled transform chain.
+type Chain []step
+
+type step struct {
+	domain.Transform
+	re *regexp.Regexp
+}
+
+// Compile checks and prepares steps. Errors name the step index.
+func Compile(steps []domain.Transform) (Chain, error) {
+	out := make(Chain, 0, len(steps))
+	for i, t := range steps {
+		s := step{Transform: t}
+		if t.From != "" && !isField(t.From) {
+			return nil, stepErr(i, "unknown from field %q", t.From)
+		}
+		switch t.Op {
+		case OpRegexExtract, OpRegexReplace:
+			if t.Pattern == "" {
+				return nil, stepErr(i, "%s needs a pattern", t.Op)
+			}
+			re, err := regexp.Compile(t.Pattern)
+			if err != nil {
+				return nil, stepErr(i, "invalid pattern: %v", err)
+			}
+			if t.Op == OpRegexExtract && (t.Group < 0 || t.Group > re.NumSubexp()) {
+				return nil, stepErr(i, "pattern has no group %d", t.Group)
+			}
+			s.re = re
+		case OpStripPrefix, OpStripSuffix:
+			if len(t.Values) == 0 {
+				return nil, stepErr(i, "%s needs values", t.Op)
+			}
+		case OpSplit:
+			if t.Sep == "" {
+				return nil, stepErr(i, "split needs sep")
+			}
+		case OpMap:
+			if len(t.Map) == 0 {
+				return nil, stepErr(i, "map needs map")
+			}
+		case OpTrim, OpLower, OpUpper, OpCollapseWhitespace:
+		default:
+			return nil, stepErr(i, "unknown op %q", t.Op)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// Apply runs the chain on v. cells holds the untransformed value of every
+// field, for steps with From.
+func (c Chain) Apply(v string, cells map[string]string) string {
+	for _, s := range c {
+		if s.From != "" {
+			v = cells[s.From]
+		}
+		v = s.apply(v)
+	}
+	return v
+}
+
+func (s step) apply(v string) string {
+	switch s.Op {
+	case OpRegexExtract:
+		m := s.re.FindStringSubmatch(v)
+		if m == nil {
+			return s.fallback("")
+		}
+		g := s.Group
+		if g == 0 && len(m) > 1 {
+			g = 1
+		}
+		return m[g]
+	case OpRegexReplace:
+		return s.re.ReplaceAllString(v, s.Replace)
+	case OpTrim:
+		if s.Chars == "" {
+			return strings.TrimSpace(v)
+		}
+		return strings.Trim(v, s.Chars)
+	case OpLower:
+		return strings.ToLower(v)
+	case OpUpper:
+		return strings.ToUpper(v)
+	case OpCollapseWhitespace:
+		return strings.Join(strings.Fields(v), " ")
+	case OpStripPrefix:
+		for _, p := range s.Values {
+			if rest, ok := s.cutPrefix(v, p); ok {
+				return rest
+			}
+		}
+	case OpStripSuffix:
+		for _, p := range s.Values {
+			if rest, ok := s.cutSuffix(v, p); ok {
+				return rest
+			}
+		}
+	case OpSplit:
+		parts := strings.Split(v, s.Sep)
+		i := s.Index
+		if i < 0 {
+			i += len(parts)
+		}
+		if i < 0 || i >= len(parts) {
+			return s.fallback("")
+		}
+		return parts[i]
+	case OpMap:
+		if m, ok := s.Map[v]; ok {
+			return m
+		}
+		return s.fallback(v)
+	}
+	return v
+}
+
+// cutPrefix compares rune by rune with IgnoreCase: a case pair can differ
+// in byte length ("K" and the Kelvin sign), so slicing v at len(p) could
+// miss a match or split a rune.
+func (s step) cutPrefix(v, p string) (string, bool) {
+	if !s.IgnoreCase {
+		return strings.CutPrefix(v, p)
+	}
+	rest := v
+	for p != "" {
+		r, n := utf8.DecodeRuneInString(rest)
+		q, m := utf8.DecodeRuneInString(p)
+		if rest == "" || !strings.EqualFold(string(r), string(q)) {
+			return v, false
+		}
+		rest, p = rest[n:], p[m:]
+	}
+	return rest, true
+}
+
+func (s step) cutSuffix(v, p string) (string, bool) {
+	if !s.IgnoreCase {
+		return strings.CutSuffix(v, p)
+	}
+	rest := v
+	for p != "" {
+		r, n := utf8.DecodeLastRuneInString(rest)
+		q, m := utf8.DecodeLastRuneInString(p)
+		if rest == "" || !strings.EqualFold(string(r), string(q)) {
+			return v, false
+		}
+		rest, p = rest[:len(rest)-n], p[:len(p)-m]
+	}
+	return rest, true
+}
+
+func (s step) fallback(v string) string {
+	if s.Default != nil {
+		return *s.Default
+	}
+	return v
+}
+
+func isField(name string) bool {
+	for _, f := range Fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Set holds the compiled chains of one template, by field.
+type Set map[string]Chain
+
+// CompileAll compiles every chain of cols.Transforms.
+func CompileAll(cols domain.CSVColumns) (Set, error) {
+	set := Set{}
+	for field, steps := range cols.Transforms {
+		if !isField(field) {
+			return nil, fmt.Errorf("transforms.%s: unknown field", field)
+		}
+		c, err := Compile(steps)
+		if err != nil {
+			return nil, fmt.Errorf("transforms.%s%w", field, err)
+		}
+		set[field] = c
+	}
+	return set, nil
+}
+
+// Apply returns cells with every chain in the set applied; fields without a
+// chain keep their value.
+func (s Set) Apply(cells map[string]string) map[string]string {
+	out := make(map[string]string, len(cells))
+	for k, v := range cells {
+		out[k] = v
+	}
+	for _, f := range Fields {
+		if c, ok := s[f]; ok {
+			out[f] = c.Apply(cells[f], cells)
+		}
+	}
+	return out
+}
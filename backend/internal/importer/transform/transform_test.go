@@ -0,0 +1,139 @@
+package transform
+
+import (
+	"errors"
+	"testing"
+	"unicode/utf8"
+
+	"bankdash/backend/internal/domain"
+)
+
+func str(s string) *string { return &s }
+
+func TestChainApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []domain.Transform
+		in    string
+		want  string
+	}{
+		{"extract first group by default", []domain.Transform{{Op: OpRegexExtract, Pattern: `REF (\d+)`}}, "Kauf REF 1234 ok", "1234"},
+		{"extract another group", []domain.Transform{{Op: OpRegexExtract, Pattern: `(\w+)-(\d+)`, Group: 2}}, "AB-12", "12"},
+		{"extract whole match without groups", []domain.Transform{{Op: OpRegexExtract, Pattern: `\d+`}}, "x 42 y", "42"},
+		{"extract without a match", []domain.Transform{{Op: OpRegexExtract, Pattern: `\d+`}}, "none", ""},
+		{"extract default", []domain.Transform{{Op: OpRegexExtract, Pattern: `\d+`, Default: str("n/a")}}, "none", "n/a"},
+		{"replace with groups", []domain.Transform{{Op: OpRegexReplace, Pattern: `(\d{2})\.(\d{2})`, Replace: "$2/$1"}}, "am 24.12.", "am 12/24."},
+		{"trim whitespace", []domain.Transform{{Op: OpTrim}}, " \tREWE \n", "REWE"},
+		{"trim chars", []domain.Transform{{Op: OpTrim, Chars: "*"}}, "**REWE*", "REWE"},
+		{"lower", []domain.Transform{{Op: OpLower}}, "BÄCKEREI", "bäckerei"},
+		{"upper", []domain.Transform{{Op: OpUpper}}, "bäckerei", "BÄCKEREI"},
+		{"collapse whitespace", []domain.Transform{{Op: OpCollapseWhitespace}}, "  REWE   Markt\t GmbH ", "REWE Markt GmbH"},
+		{"strip the first matching prefix", []domain.Transform{{Op: OpStripPrefix, Values: []string{"SEPA-", "SEPA-LASTSCHRIFT "}}}, "SEPA-LASTSCHRIFT Telekom", "LASTSCHRIFT Telekom"},
+		{"strip prefix", []domain.Transform{{Op: OpStripPrefix, Values: []string{"PayPal *", "SEPA-LASTSCHRIFT "}}}, "PayPal *Steam", "Steam"},
+		{"strip prefix is case sensitive", []domain.Transform{{Op: OpStripPrefix, Values: []string{"paypal *"}}}, "PayPal *Steam", "PayPal *Steam"},
+		{"strip prefix ignoring case", []domain.Transform{{Op: OpStripPrefix, Values: []string{"paypal *"}, IgnoreCase: true}}, "PayPal *Steam", "Steam"},
+		{"strip suffix", []domain.Transform{{Op: OpStripSuffix, Values: []string{" GmbH"}}}, "Telekom GmbH", "Telekom"},
+		{"strip suffix ignoring case", []domain.Transform{{Op: OpStripSuffix, Values: []string{"öl"}, IgnoreCase: true}}, "HEIZÖL", "HEIZ"},
+		{"split", []domain.Transform{{Op: OpSplit, Sep: "/", Index: 1}}, "Amazon/Marketplace/DE", "Marketplace"},
+		{"split from the end", []domain.Transform{{Op: OpSplit, Sep: "/", Index: -1}}, "Amazon/Marketplace/DE", "DE"},
+		{"split out of range", []domain.Transform{{Op: OpSplit, Sep: "/", Index: -4}}, "Amazon/Marketplace/DE", ""},
+		{"split default", []domain.Transform{{Op: OpSplit, Sep: "/", Index: 3, Default: str("?")}}, "Amazon/Marketplace/DE", "?"},
+		{"map", []domain.Transform{{Op: OpMap, Map: map[string]string{"S": "booked", "V": "pending"}}}, "V", "pending"},
+		{"map keeps unmapped values", []domain.Transform{{Op: OpMap, Map: map[string]string{"S": "booked"}}}, "X", "X"},
+		{"map default", []domain.Transform{{Op: OpMap, Map: map[string]string{"S": "booked"}, Default: str("pending")}}, "X", "pending"},
+		{"chain", []domain.Transform{
+			{Op: OpStripPrefix, Values: []string{"VISA "}},
+			{Op: OpRegexReplace, Pattern: `\s+\d+$`},
+			{Op: OpCollapseWhitespace},
+		}, "VISA MIX  MARKT 54", "MIX MARKT"},
+	}
+	for _, tt := range tests {
+		c, err := Compile(tt.steps)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := c.Apply(tt.in, nil); got != tt.want {
+			t.Errorf("%s: Apply(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestStripIgnoreCaseMultiByte(t *testing.T) {
+	tests := []struct {
+		op, value, in, want string
+	}{
+		// the Kelvin sign (3 bytes) folds to k (1 byte)
+		{OpStripPrefix, "k", "KELVIN", "ELVIN"},
+		{OpStripPrefix, "K", "kelvin", "elvin"},
+		{OpStripSuffix, "k", "LIDL K", "LIDL "},
+		{OpStripPrefix, "ä", "Äpfel", "pfel"},
+		// a's byte length ends inside Ä: no match, no broken rune
+		{OpStripPrefix, "a", "Äpfel", "Äpfel"},
+		{OpStripSuffix, "l", "ÖL€", "ÖL€"},
+		{OpStripPrefix, "straße ", "STRASSE Lidl", "STRASSE Lidl"},
+	}
+	for _, tt := range tests {
+		c, err := Compile([]domain.Transform{{Op: tt.op, Values: []string{tt.value}, IgnoreCase: true}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := c.Apply(tt.in, nil)
+		if got != tt.want || !utf8.ValidString(got) {
+			t.Errorf("%s %q on %q = %q, want %q", tt.op, tt.value, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSetFromReadsUntransformedCell(t *testing.T) {
+	set, err := CompileAll(domain.CSVColumns{Transforms: map[string][]domain.Transform{
+		"memo":      {{Op: OpRegexReplace, Pattern: `EREF: \S+ ?`}},
+		"reference": {{Op: OpRegexExtract, From: "memo", Pattern: `EREF: (\S+)`}},
+		"payee":     {{Op: OpUpper}, {Op: OpRegexExtract, From: "memo", Pattern: `^(\w+)`}, {Op: OpLower}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cells := map[string]string{"payee": "", "memo": "Telekom EREF: 4711 Rechnung", "amount": "-39,95"}
+	got := set.Apply(cells)
+	want := map[string]string{"payee": "telekom", "memo": "Telekom Rechnung", "reference": "4711", "amount": "-39,95"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+	if cells["memo"] != "Telekom EREF: 4711 Rechnung" {
+		t.Errorf("Apply changed its input: %q", cells["memo"])
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		steps []domain.Transform
+		index int
+	}{
+		{[]domain.Transform{{Op: OpRegexExtract}}, 0},
+		{[]domain.Transform{{Op: OpTrim}, {Op: OpRegexReplace, Pattern: "("}}, 1},
+		{[]domain.Transform{{Op: OpRegexExtract, Pattern: `(\d+)`, Group: 2}}, 0},
+		{[]domain.Transform{{Op: OpRegexExtract, Pattern: `(\d+)`, Group: -1}}, 0},
+		{[]domain.Transform{{Op: OpStripPrefix}}, 0},
+		{[]domain.Transform{{Op: OpLower}, {Op: OpUpper}, {Op: OpSplit}}, 2},
+		{[]domain.Transform{{Op: OpMap}}, 0},
+		{[]domain.Transform{{Op: "reverse"}}, 0},
+		{[]domain.Transform{{Op: OpTrim, From: "comment"}}, 0},
+	}
+	for _, tt := range tests {
+		_, err := Compile(tt.steps)
+		var se *StepError
+		if !errors.As(err, &se) || se.Index != tt.index {
+			t.Errorf("Compile(%+v) = %v, want an error at step %d", tt.steps, err, tt.index)
+		}
+	}
+
+	_, err := CompileAll(domain.CSVColumns{Transforms: map[string][]domain.Transform{"payee": {{Op: OpTrim}, {Op: OpSplit}}}})
+	if err == nil || err.Error() != "transforms.payee[1]: split needs sep" {
+		t.Errorf("CompileAll() = %v", err)
+	}
+	if _, err := CompileAll(domain.CSVColumns{Transforms: map[string][]domain.Transform{"comment": {{Op: OpTrim}}}}); err == nil {
+		t.Error("CompileAll() accepted an unknown field")
+	}
+}
@@ -2,6 +2,7 @@ package influx
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"bankdash/backend/internal/config"
@@ -14,22 +15,33 @@ import (
 type Client struct {
 	raw    influxdb2.Client
 	write  api.WriteAPIBlocking
+	query  api.QueryAPI
 	org    string
 	bucket string
+	// points written before booking_date was a field were placed in days
+	// of the server's default zone
+	legacyLoc *time.Location
 }
 
 func New(cfg config.Config) (*Client, error) {
+	loc, err := time.LoadLocation(cfg.DefaultTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DEFAULT_TIMEZONE %q: %w", cfg.DefaultTimezone, err)
+	}
 	c := influxdb2.NewClient(cfg.InfluxURL, cfg.InfluxToken)
 	// Blocking writer is simplest for MVP; we can switch to batched async later. :contentReference[oaicite:6]{index=6}
 	w := c.WriteAPIBlocking(cfg.InfluxOrg, cfg.InfluxBucket)
+	q := c.QueryAPI(cfg.InfluxOrg)
 
 	// quick sanity ping: try a lightweight health endpoint would be nicer,
 	// but client doesn't expose it directly. We'll just return and rely on errors at first write.
 	return &Client{
-		raw:    c,
-		write:  w,
-		org:    cfg.InfluxOrg,
-		bucket: cfg.InfluxBucket,
+		raw:       c,
+		write:     w,
+		query:     q,
+		org:       cfg.InfluxOrg,
+		bucket:    cfg.InfluxBucket,
+		legacyLoc: loc,
 	}, nil
 }
 
@@ -40,3 +52,22 @@ func (c *Client) WritePoint(ctx context.Context, p *write.Point) error {
 	defer cancel()
 	return c.write.WritePoint(ctx, p)
 }
+
+// DeleteTxPoint removes the single bank_tx point of an account at ts. Needed
+// when a rewrite changes tags, since that would otherwise create a second series.
+func (c *Client) DeleteTxPoint(ctx context.Context, accountID string, ts time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	pred := fmt.Sprintf(`_measurement=%q AND account_id=%q`, MeasurementTx, accountID)
+	return c.raw.DeleteAPI().DeleteWithName(ctx, c.org, c.bucket, ts, ts.Add(time.Nanosecond), pred)
+}
+
+// WritePoints writes a batch in one request.
+func (c *Client) WritePoints(ctx context.Context, ps []*write.Point) error {
+	if len(ps) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	return c.write.WritePoint(ctx, ps...)
+}
@@ -0,0 +1,206 @@
+package influx
+
+import (
+	"encoding/binary"
+	"maps"
+	"strings"
+	"time"
+
+	"bankdash/backend/internal/domain"
+
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+)
+
+const MeasurementTx = "bank_tx"
+
+// TxPoint maps a transaction to a bank_tx point at ts (usually PointTime(tx)).
+func TxPoint(tx domain.Transaction, ts time.Time) *write.Point {
+	fields := map[string]any{
+		"amount_cents":     tx.AmountCents,
+		"amount_cents_abs": abs64(tx.AmountCents),
+		"payee":            tx.Payee,
+		"memo":             tx.Memo,
+		"reference":        tx.Reference,
+		"iban":             tx.IBAN,
+		"tx_uid":           tx.TxUID,
+		"booking_date":     tx.BookingDate.Format("2006-01-02"),
+	}
+	if tx.BalanceCents != nil {
+		fields["balance_cents"] = *tx.BalanceCents
+	}
+	if tx.PurchaseDate != nil {
+		fields["purchase_date"] = tx.PurchaseDate.UTC().Format(time.RFC3339Nano)
+	}
+	if tx.CardLast4 != "" {
+		fields["card_last4"] = tx.CardLast4
+		fields["merchant_city"] = tx.MerchantCity
+		fields["merchant_country"] = tx.MerchantCountry
+	}
+	if tx.ARN != "" {
+		fields["arn"] = tx.ARN
+	}
+	if tx.TransactionTime != nil {
+		fields["tx_time"] = tx.TransactionTime.UTC().Format(time.RFC3339Nano)
+	}
+	if tx.AmountDecimal != "" {
+		fields["amount_decimal"] = tx.AmountDecimal
+	}
+	if tx.Status != "" {
+		fields["status"] = tx.Status
+	}
+	if tx.ReplacesUID != "" {
+		fields["replaces_uid"] = tx.ReplacesUID
+	}
+	if tx.ImportBatchID != "" {
+		fields["import_batch_id"] = tx.ImportBatchID
+	}
+	if tx.OriginalCurrency != "" {
+		fields["orig_amount_cents"] = tx.OriginalAmountCents
+		fields["orig_currency"] = tx.OriginalCurrency
+		if tx.OriginalAmountDecimal != "" {
+			fields["orig_amount_decimal"] = tx.OriginalAmountDecimal
+		}
+	}
+	if tx.FXRate != 0 {
+		fields["fx_rate"] = tx.FXRate
+	}
+	if tx.FXFeeCents != 0 {
+		fields["fx_fee_cents"] = tx.FXFeeCents
+	}
+	if len(tx.Flags) > 0 {
+		fields["flags"] = strings.Join(tx.Flags, ",")
+	}
+	if tx.TransferPeerUID != "" {
+		fields["transfer_peer_uid"] = tx.TransferPeerUID
+		fields["transfer_account_id"] = tx.TransferAccountID
+	}
+
+	return influxdb2.NewPoint(MeasurementTx, txTags(tx), fields, ts)
+}
+
+// txTags is the series key of a bank_tx point.
+func txTags(tx domain.Transaction) map[string]string {
+	return map[string]string{
+		"tenant_id":   tx.TenantID,
+		"account_id":  tx.AccountID,
+		"bank_id":     tx.BankID,
+		"currency":    tx.Currency,
+		"direction":   tx.Direction,
+		"category_id": tx.CategoryID,
+		"merchant":    tx.Merchant,
+	}
+}
+
+// SameSeries reports whether a and b are written with the same tags. When
+// they differ (an edited merchant alias, a new category), writing b at a's
+// time adds a second point instead of overwriting a, so a has to be
+// deleted first.
+func SameSeries(a, b domain.Transaction) bool {
+	return maps.Equal(txTags(a), txTags(b))
+}
+
+// PointTime is deterministic so re-imports overwrite instead of duplicating.
+// Bookings with a known TransactionTime (opt-in per template) use that time
+// plus a sub-second offset from the txUID to keep same-second bookings
+// apart; everything else, card purchases included, lands within the booking
+// day at midnight + (first 8 bytes of txUID) mod 1 day. The purchase time
+// of card payments is stored as a field instead.
+func PointTime(tx domain.Transaction) time.Time {
+	hashBytes := decodeFirst8(tx.TxUID)
+	if tx.TransactionTime != nil {
+		return tx.TransactionTime.Truncate(time.Second).Add(time.Duration(hashBytes % uint64(time.Second)))
+	}
+	offset := time.Duration(hashBytes % uint64(24*time.Hour)) // nanoseconds-ish, but duration is ns
+	return tx.BookingDate.Add(offset)
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func decodeFirst8(hexStr string) uint64 {
+	// hexStr is 64 chars (sha256). Take first 16 hex chars = 8 bytes.
+	if len(hexStr) < 16 {
+		return 0
+	}
+	var buf [8]byte
+	for i := 0; i < 8; i++ {
+		hi := fromHex(hexStr[i*2])
+		lo := fromHex(hexStr[i*2+1])
+		buf[i] = (hi << 4) | lo
+	}
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+func fromHex(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10
+	default:
+		return 0
+	}
+}
+
+const MeasurementForecast = "bank_forecast"
+
+// ForecastPoints maps a projection to one bank_forecast point per day.
+// Rerunning a forecast overwrites the same points.
+func ForecastPoints(tenantID string, fc domain.Forecast) []*write.Point {
+	out := make([]*write.Point, 0, len(fc.Points))
+	for _, p := range fc.Points {
+		out = append(out, influxdb2.NewPoint(
+			MeasurementForecast,
+			map[string]string{
+				"tenant_id":  tenantID,
+				"account_id": fc.AccountID,
+				"currency":   fc.Currency,
+			},
+			map[string]any{
+				"balance_cents":   p.BalanceCents,
+				"recurring_cents": p.RecurringCents,
+				"baseline_cents":  p.BaselineCents,
+			},
+			p.Date,
+		))
+	}
+	return out
+}
+
+const MeasurementAnomaly = "bank_anomaly"
+
+// AnomalyPoint stores a finding for Grafana annotations (text = message).
+// Findings on bookings of the same day are kept apart by a sub-second
+// offset from the txUID, like PointTime does.
+func AnomalyPoint(tenantID string, a domain.Anomaly) *write.Point {
+	fields := map[string]any{
+		"message":        a.Message,
+		"amount_cents":   a.AmountCents,
+		"expected_cents": a.ExpectedCents,
+		"score":          a.Score,
+	}
+	if a.TxUID != "" {
+		fields["tx_uid"] = a.TxUID
+	}
+	if a.Merchant != "" {
+		fields["merchant"] = a.Merchant
+	}
+	return influxdb2.NewPoint(
+		MeasurementAnomaly,
+		map[string]string{
+			"tenant_id":   tenantID,
+			"account_id":  a.AccountID,
+			"kind":        a.Kind,
+			"category_id": a.CategoryID,
+		},
+		fields,
+		a.Time.Add(time.Duration(decodeFirst8(a.TxUID)%uint64(time.Second))),
+	)
+}
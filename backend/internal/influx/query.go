@@ -0,0 +1,283 @@
+package influx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"bankdash/backend/internal/domain"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
+)
+
+// TxFilter narrows a bank_tx read. Zero values mean "no filter".
+type TxFilter struct {
+	TenantID    string
+	AccountIDs  []string
+	From        time.Time // inclusive
+	To          time.Time // exclusive
+	CategoryIDs []string
+	Direction   string // "in"|"out"
+	Status      string // domain.TxStatusBooked|TxStatusPending
+
+	// bounds on the absolute amount, inclusive; nil means unbounded
+	MinAbsCents *int64
+	MaxAbsCents *int64
+
+	Payee string // case-insensitive substring of payee or merchant
+	Memo  string // case-insensitive substring of memo
+
+	Sort  TxSort
+	Limit int       // 0 means all
+	After *TxCursor // continue behind this row in Sort order
+}
+
+// TxSort orders a read; ties are broken by point time, then tx_uid.
+type TxSort string
+
+const (
+	SortDateAsc    TxSort = "date"
+	SortDateDesc   TxSort = "-date"
+	SortAmountAsc  TxSort = "amount"
+	SortAmountDesc TxSort = "-amount"
+)
+
+func (s TxSort) Valid() bool {
+	switch s {
+	case "", SortDateAsc, SortDateDesc, SortAmountAsc, SortAmountDesc:
+		return true
+	}
+	return false
+}
+
+// TxCursor is the sort key of the last row of a page.
+type TxCursor struct {
+	Time        time.Time `json:"t"`
+	AmountCents int64     `json:"a,omitempty"`
+	TxUID       string    `json:"u"`
+}
+
+// CursorOf returns the cursor pointing behind rec.
+func CursorOf(rec TxRecord) TxCursor {
+	return TxCursor{Time: rec.Time, AmountCents: rec.Tx.AmountCents, TxUID: rec.Tx.TxUID}
+}
+
+// TxRecord is a stored transaction together with its point time, which is
+// needed to rewrite or delete exactly that point.
+type TxRecord struct {
+	Time time.Time
+	Tx   domain.Transaction
+}
+
+func (c *Client) QueryTransactions(ctx context.Context, f TxFilter) ([]TxRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var out []TxRecord
+	err := c.StreamTransactions(ctx, f, func(rec TxRecord) error {
+		out = append(out, rec)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StreamTransactions calls fn for every matching row as it arrives, without
+// holding the result in memory. It has no timeout of its own; an error from
+// fn stops the read and is returned.
+func (c *Client) StreamTransactions(ctx context.Context, f TxFilter, fn func(TxRecord) error) error {
+	res, err := c.query.Query(ctx, c.txFlux(f))
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	for res.Next() {
+		if err := fn(c.recordToTx(res.Record())); err != nil {
+			return err
+		}
+	}
+	return res.Err()
+}
+
+func (c *Client) txFlux(f TxFilter) string {
+	from := f.From
+	if from.IsZero() {
+		from = time.Unix(0, 0)
+	}
+	to := f.To
+	if to.IsZero() {
+		to = time.Now().AddDate(1, 0, 0)
+	}
+
+	var b strings.Builder
+	if f.Payee != "" || f.Memo != "" {
+		b.WriteString("import \"strings\"\n\n")
+	}
+	fmt.Fprintf(&b, "from(bucket: %s)\n", fluxString(c.bucket))
+	fmt.Fprintf(&b, "  |> range(start: %s, stop: %s)\n", from.UTC().Format(time.RFC3339Nano), to.UTC().Format(time.RFC3339Nano))
+	fmt.Fprintf(&b, "  |> filter(fn: (r) => r._measurement == %s)\n", fluxString(MeasurementTx))
+	if f.TenantID != "" {
+		fmt.Fprintf(&b, "  |> filter(fn: (r) => r.tenant_id == %s)\n", fluxString(f.TenantID))
+	}
+	if len(f.AccountIDs) > 0 {
+		b.WriteString("  |> filter(fn: (r) => " + fluxAnyEq("account_id", f.AccountIDs) + ")\n")
+	}
+	// tags can be filtered before the pivot, which is much cheaper
+	if len(f.CategoryIDs) > 0 {
+		b.WriteString("  |> filter(fn: (r) => " + fluxAnyEq("category_id", f.CategoryIDs) + ")\n")
+	}
+	if f.Direction != "" {
+		fmt.Fprintf(&b, "  |> filter(fn: (r) => r.direction == %s)\n", fluxString(f.Direction))
+	}
+	b.WriteString("  |> pivot(rowKey: [\"_time\"], columnKey: [\"_field\"], valueColumn: \"_value\")\n")
+	if f.MinAbsCents != nil {
+		fmt.Fprintf(&b, "  |> filter(fn: (r) => r.amount_cents_abs >= %d)\n", *f.MinAbsCents)
+	}
+	if f.MaxAbsCents != nil {
+		fmt.Fprintf(&b, "  |> filter(fn: (r) => r.amount_cents_abs <= %d)\n", *f.MaxAbsCents)
+	}
+	if f.Payee != "" {
+		needle := fluxString(strings.ToLower(f.Payee))
+		fmt.Fprintf(&b, "  |> filter(fn: (r) => (exists r.payee and strings.containsStr(v: strings.toLower(v: r.payee), substr: %s))"+
+			" or (exists r.merchant and strings.containsStr(v: strings.toLower(v: r.merchant), substr: %s)))\n", needle, needle)
+	}
+	// points written before statuses existed are booked
+	switch f.Status {
+	case domain.TxStatusPending:
+		fmt.Fprintf(&b, "  |> filter(fn: (r) => exists r.status and r.status == %s)\n", fluxString(domain.TxStatusPending))
+	case domain.TxStatusBooked:
+		fmt.Fprintf(&b, "  |> filter(fn: (r) => not exists r.status or r.status != %s)\n", fluxString(domain.TxStatusPending))
+	}
+	if f.Memo != "" {
+		fmt.Fprintf(&b, "  |> filter(fn: (r) => exists r.memo and strings.containsStr(v: strings.toLower(v: r.memo), substr: %s))\n",
+			fluxString(strings.ToLower(f.Memo)))
+	}
+	b.WriteString("  |> group()\n")
+
+	desc := f.Sort == SortDateDesc || f.Sort == SortAmountDesc
+	byAmount := f.Sort == SortAmountAsc || f.Sort == SortAmountDesc
+	if f.After != nil {
+		b.WriteString("  |> filter(fn: (r) => " + afterFlux(*f.After, byAmount, desc) + ")\n")
+	}
+	cols := `"_time", "tx_uid"`
+	if byAmount {
+		cols = `"amount_cents", ` + cols
+	}
+	fmt.Fprintf(&b, "  |> sort(columns: [%s], desc: %t)\n", cols, desc)
+	if f.Limit > 0 {
+		fmt.Fprintf(&b, "  |> limit(n: %d)\n", f.Limit)
+	}
+	return b.String()
+}
+
+// afterFlux renders the keyset condition for rows behind c.
+func afterFlux(c TxCursor, byAmount, desc bool) string {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	t := fmt.Sprintf("time(v: %s)", fluxString(c.Time.UTC().Format(time.RFC3339Nano)))
+	uid := fluxString(c.TxUID)
+	cond := fmt.Sprintf("r._time %s %s or (r._time == %s and r.tx_uid %s %s)", op, t, t, op, uid)
+	if byAmount {
+		cond = fmt.Sprintf("r.amount_cents %s %d or (r.amount_cents == %d and (%s))", op, c.AmountCents, c.AmountCents, cond)
+	}
+	return cond
+}
+
+func (c *Client) recordToTx(r *query.FluxRecord) TxRecord {
+	ts := r.Time()
+	tx := domain.Transaction{
+		TenantID:          str(r, "tenant_id"),
+		AccountID:         str(r, "account_id"),
+		BankID:            str(r, "bank_id"),
+		Currency:          str(r, "currency"),
+		Direction:         str(r, "direction"),
+		CategoryID:        str(r, "category_id"),
+		Merchant:          str(r, "merchant"),
+		AmountCents:       i64(r, "amount_cents"),
+		Payee:             str(r, "payee"),
+		Memo:              str(r, "memo"),
+		Reference:         str(r, "reference"),
+		IBAN:              str(r, "iban"),
+		TxUID:             str(r, "tx_uid"),
+		CardLast4:         str(r, "card_last4"),
+		MerchantCity:      str(r, "merchant_city"),
+		MerchantCountry:   str(r, "merchant_country"),
+		ARN:               str(r, "arn"),
+		OriginalCurrency:  str(r, "orig_currency"),
+		AmountDecimal:     str(r, "amount_decimal"),
+		FXFeeCents:        i64(r, "fx_fee_cents"),
+		TransferPeerUID:   str(r, "transfer_peer_uid"),
+		TransferAccountID: str(r, "transfer_account_id"),
+		ImportBatchID:     str(r, "import_batch_id"),
+		ReplacesUID:       str(r, "replaces_uid"),
+		Status:            str(r, "status"),
+	}
+	if tx.Status == "" {
+		tx.Status = domain.TxStatusBooked
+	}
+	tx.OriginalAmountCents = i64(r, "orig_amount_cents")
+	tx.OriginalAmountDecimal = str(r, "orig_amount_decimal")
+	if v, ok := r.ValueByKey("balance_cents").(int64); ok {
+		tx.BalanceCents = &v
+	}
+	if v := str(r, "flags"); v != "" {
+		tx.Flags = strings.Split(v, ",")
+	}
+	if v, ok := r.ValueByKey("fx_rate").(float64); ok {
+		tx.FXRate = v
+	}
+
+	// booking_date is a calendar date; it comes back as midnight UTC
+	if d, err := time.Parse("2006-01-02", str(r, "booking_date")); err == nil {
+		tx.BookingDate = d
+	} else {
+		l := ts.In(c.legacyLoc)
+		tx.BookingDate = time.Date(l.Year(), l.Month(), l.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	if t, err := time.Parse(time.RFC3339Nano, str(r, "tx_time")); err == nil {
+		tx.TransactionTime = &t
+	}
+	if t, err := time.Parse(time.RFC3339Nano, str(r, "purchase_date")); err == nil {
+		tx.PurchaseDate = &t
+	}
+	return TxRecord{Time: ts, Tx: tx}
+}
+
+func str(r *query.FluxRecord, key string) string {
+	if v, ok := r.ValueByKey(key).(string); ok {
+		return v
+	}
+	return ""
+}
+
+func i64(r *query.FluxRecord, key string) int64 {
+	switch v := r.ValueByKey(key).(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// fluxString quotes s as a Flux string literal. Flux params are a Cloud-only
+// feature, so OSS queries have to be built as text.
+func fluxString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "${", `\${`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// fluxAnyEq renders `r.col == "a" or r.col == "b"`.
+func fluxAnyEq(col string, vals []string) string {
+	parts := make([]string, 0, len(vals))
+	for _, v := range vals {
+		parts = append(parts, "r."+col+" == "+fluxString(v))
+	}
+	return strings.Join(parts, " or ")
+}
@@ -0,0 +1,201 @@
+package merchant
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"bankdash/backend/internal/domain"
+)
+
+// card scheme / acquirer prefixes that banks put in front of the real payee
+var builtinPrefixes = []string{
+	"VISA ",
+	"MASTERCARD ",
+	"MAESTRO ",
+	"GIROCARD ",
+	"EC ",
+	"SUMUP *",
+	"SQ *",
+	"PAYPAL *",
+	"ZETTLE_*",
+}
+
+// branch markers followed by a number, e.g. "KIK FIL 0988", "MIX MARKT 54"
+var branchWords = map[string]bool{
+	"FIL":     true,
+	"FIL.":    true,
+	"FILIALE": true,
+	"NR":      true,
+	"NR.":     true,
+}
+
+// legal form suffixes dropped for display
+var legalSuffixes = []string{
+	"GMBH & CO. KG",
+	"GMBH & CO KG",
+	"NIEDERLASSUNG DEUTSCHLAND",
+	"S.A R.L.",
+	"S.A.R.L.",
+	"S.C.A.",
+	"GMBH",
+	"AG",
+	"SE",
+	"KG",
+	"E.K.",
+	"E.V.",
+	"LTD",
+	"LTD.",
+	"INC",
+	"INC.",
+	"B.V.",
+	"AB",
+}
+
+// well-known chains; user aliases take precedence over these
+var builtinAliases = []domain.MerchantAlias{
+	{Match: "AMAZON", Merchant: "Amazon"},
+	{Match: "AMZN", Merchant: "Amazon"},
+	{Match: "ALDI", Merchant: "Aldi"},
+	{Match: "LIDL", Merchant: "Lidl"},
+	{Match: "NETTO", Merchant: "Netto"},
+	{Match: "PENNY", Merchant: "Penny"},
+	{Match: "REWE", Merchant: "Rewe"},
+	{Match: "EDEKA", Merchant: "Edeka"},
+	{Match: "KAUFLAND", Merchant: "Kaufland"},
+	{Match: "ROSSMANN", Merchant: "Rossmann"},
+	{Match: "DM-DROGERIE", Merchant: "dm"},
+	{Match: "DM DROGERIE", Merchant: "dm"},
+	{Match: "KIK", Merchant: "KiK"},
+	{Match: "TEDI", Merchant: "TEDi"},
+	{Match: "IKEA", Merchant: "IKEA"},
+	{Match: "PAYPAL", Merchant: "PayPal"},
+	{Match: "NETFLIX", Merchant: "Netflix"},
+	{Match: "SPOTIFY", Merchant: "Spotify"},
+	{Match: "GOOGLE", Merchant: "Google"},
+	{Match: "APPLE.COM", Merchant: "Apple"},
+}
+
+// Normalizer turns raw payee strings into clean merchant names.
+type Normalizer struct {
+	aliases []domain.MerchantAlias // user first, then builtin; longest match first within each group
+}
+
+// NewNormalizer builds a normalizer from user aliases (may be nil).
+func NewNormalizer(userAliases []domain.MerchantAlias) *Normalizer {
+	user := prepareAliases(userAliases)
+	builtin := prepareAliases(builtinAliases)
+	return &Normalizer{aliases: append(user, builtin...)}
+}
+
+// Normalize returns the merchant name for a payee, or "" if payee is empty.
+func (n *Normalizer) Normalize(payee string) string {
+	cleaned := Clean(payee)
+	if cleaned == "" {
+		return ""
+	}
+	up := strings.ToUpper(cleaned)
+	for _, a := range n.aliases {
+		if hasWordPrefix(up, a.Match) {
+			return a.Merchant
+		}
+	}
+	return displayName(cleaned)
+}
+
+// Clean strips card prefixes, branch numbers and legal suffixes but keeps
+// the original casing. Exposed so alias matches can be previewed.
+func Clean(payee string) string {
+	s := strings.Join(strings.Fields(payee), " ")
+	if s == "" {
+		return ""
+	}
+
+	for _, p := range builtinPrefixes {
+		if len(s) > len(p) && strings.EqualFold(s[:len(p)], p) {
+			s = strings.TrimSpace(s[len(p):])
+			break
+		}
+	}
+
+	s = stripBranch(s)
+
+	for _, suf := range legalSuffixes {
+		if hasSuffixFold(s, " "+suf) {
+			s = strings.TrimRight(s[:len(s)-len(suf)], ", ")
+		}
+	}
+	return s
+}
+
+func hasSuffixFold(s, suffix string) bool {
+	return len(s) > len(suffix) && strings.EqualFold(s[len(s)-len(suffix):], suffix)
+}
+
+func stripBranch(s string) string {
+	words := strings.Fields(s)
+	for len(words) > 1 {
+		last := words[len(words)-1]
+		if !isDigits(last) {
+			break
+		}
+		words = words[:len(words)-1]
+		if len(words) > 1 && branchWords[strings.ToUpper(words[len(words)-1])] {
+			words = words[:len(words)-1]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func prepareAliases(in []domain.MerchantAlias) []domain.MerchantAlias {
+	out := make([]domain.MerchantAlias, 0, len(in))
+	for _, a := range in {
+		m := strings.ToUpper(strings.Join(strings.Fields(a.Match), " "))
+		if m == "" || strings.TrimSpace(a.Merchant) == "" {
+			continue
+		}
+		out = append(out, domain.MerchantAlias{Match: m, Merchant: strings.TrimSpace(a.Merchant)})
+	}
+	// longest match wins ("DM-DROGERIE" before "DM")
+	sort.SliceStable(out, func(i, j int) bool { return len(out[i].Match) > len(out[j].Match) })
+	return out
+}
+
+// hasWordPrefix reports whether s starts with prefix followed by a word boundary.
+func hasWordPrefix(s, prefix string) bool {
+	if !strings.HasPrefix(s, prefix) {
+		return false
+	}
+	if len(s) == len(prefix) {
+		return true
+	}
+	r := rune(s[len(prefix)])
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// displayName title-cases shouting payees ("OCTOPUS ENERGY" -> "Octopus Energy")
+// and leaves mixed-case ones alone.
+func displayName(s string) string {
+	if s != strings.ToUpper(s) {
+		return s
+	}
+	words := strings.Fields(strings.ToLower(s))
+	for i, w := range words {
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		words[i] = string(r)
+	}
+	return strings.Join(words, " ")
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
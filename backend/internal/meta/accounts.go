@@ -0,0 +1,73 @@
+package meta
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"bankdash/backend/internal/domain"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func (s *Store) UpsertAccount(a domain.Account) error {
+	if strings.TrimSpace(a.ID) == "" {
+		return fmt.Errorf("account id is required")
+	}
+	if a.Timezone != "" {
+		if _, err := time.LoadLocation(a.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q", a.Timezone)
+		}
+	}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucketAccounts))
+		return bk.Put([]byte(a.ID), b)
+	})
+}
+
+func (s *Store) GetAccount(id string) (*domain.Account, error) {
+	var out domain.Account
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucketAccounts))
+		raw := bk.Get([]byte(id))
+		if raw == nil {
+			return fmt.Errorf("account not found: %s", id)
+		}
+		return json.Unmarshal(raw, &out)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *Store) DeleteAccount(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucketAccounts))
+		if bk.Get([]byte(id)) == nil {
+			return fmt.Errorf("account not found: %s", id)
+		}
+		return bk.Delete([]byte(id))
+	})
+}
+
+func (s *Store) ListAccounts() ([]domain.Account, error) {
+	var res []domain.Account
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucketAccounts))
+		return bk.ForEach(func(k, v []byte) error {
+			var a domain.Account
+			if err := json.Unmarshal(v, &a); err != nil {
+				return err
+			}
+			res = append(res, a)
+			return nil
+		})
+	})
+	return res, err
+}
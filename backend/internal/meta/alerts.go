@@ -0,0 +1,136 @@
+package meta
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"bankdash/backend/internal/domain"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func (s *Store) UpsertAlertRule(r domain.AlertRule) error {
+	if strings.TrimSpace(r.ID) == "" {
+		return fmt.Errorf("alert rule id is required")
+	}
+	return s.putJSON(bucketAlertRules, r.ID, r)
+}
+
+func (s *Store) DeleteAlertRule(id string) error {
+	return s.deleteKey(bucketAlertRules, id, "alert rule")
+}
+
+func (s *Store) ListAlertRules() ([]domain.AlertRule, error) {
+	var res []domain.AlertRule
+	err := s.forEachJSON(bucketAlertRules, func(v []byte) error {
+		var r domain.AlertRule
+		if err := json.Unmarshal(v, &r); err != nil {
+			return err
+		}
+		res = append(res, r)
+		return nil
+	})
+	return res, err
+}
+
+func (s *Store) UpsertAlertChannel(c domain.AlertChannel) error {
+	if strings.TrimSpace(c.ID) == "" {
+		return fmt.Errorf("alert channel id is required")
+	}
+	return s.putJSON(bucketAlertChannels, c.ID, c)
+}
+
+func (s *Store) GetAlertChannel(id string) (*domain.AlertChannel, error) {
+	var out domain.AlertChannel
+	err := s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket([]byte(bucketAlertChannels)).Get([]byte(id))
+		if raw == nil {
+			return fmt.Errorf("alert channel not found: %s", id)
+		}
+		return json.Unmarshal(raw, &out)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *Store) DeleteAlertChannel(id string) error {
+	return s.deleteKey(bucketAlertChannels, id, "alert channel")
+}
+
+func (s *Store) ListAlertChannels() ([]domain.AlertChannel, error) {
+	var res []domain.AlertChannel
+	err := s.forEachJSON(bucketAlertChannels, func(v []byte) error {
+		var c domain.AlertChannel
+		if err := json.Unmarshal(v, &c); err != nil {
+			return err
+		}
+		res = append(res, c)
+		return nil
+	})
+	return res, err
+}
+
+// AlertSent reports whether an alert with this key was delivered before.
+func (s *Store) AlertSent(key string) (bool, error) {
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket([]byte(bucketAlertsSent)).Get([]byte(key)) != nil
+		return nil
+	})
+	return found, err
+}
+
+func (s *Store) RecordAlert(a domain.Alert) error {
+	return s.putJSON(bucketAlertsSent, a.Key, a)
+}
+
+// ListAlerts returns delivered alerts, newest first.
+func (s *Store) ListAlerts(limit int) ([]domain.Alert, error) {
+	var res []domain.Alert
+	err := s.forEachJSON(bucketAlertsSent, func(v []byte) error {
+		var a domain.Alert
+		if err := json.Unmarshal(v, &a); err != nil {
+			return err
+		}
+		res = append(res, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].At.After(res[j].At) })
+	if limit > 0 && len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+func (s *Store) putJSON(bucket, key string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(bucket)).Put([]byte(key), b)
+	})
+}
+
+func (s *Store) deleteKey(bucket, key, what string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucket))
+		if bk.Get([]byte(key)) == nil {
+			return fmt.Errorf("%s not found: %s", what, key)
+		}
+		return bk.Delete([]byte(key))
+	})
+}
+
+func (s *Store) forEachJSON(bucket string, fn func(v []byte) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(bucket)).ForEach(func(_, v []byte) error { return fn(v) })
+	})
+}
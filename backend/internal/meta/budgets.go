@@ -0,0 +1,74 @@
+package meta
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"bankdash/backend/internal/domain"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// UpsertBudget keeps the original CreatedAt when a budget is edited.
+func (s *Store) UpsertBudget(b domain.Budget) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucketBudgets))
+		if raw := bk.Get([]byte(b.ID)); raw != nil {
+			var old domain.Budget
+			if err := json.Unmarshal(raw, &old); err == nil && !old.CreatedAt.IsZero() {
+				b.CreatedAt = old.CreatedAt
+			}
+		}
+		if b.CreatedAt.IsZero() {
+			b.CreatedAt = time.Now().UTC()
+		}
+		raw, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		return bk.Put([]byte(b.ID), raw)
+	})
+}
+
+func (s *Store) GetBudget(id string) (*domain.Budget, error) {
+	var out domain.Budget
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucketBudgets))
+		raw := bk.Get([]byte(id))
+		if raw == nil {
+			return fmt.Errorf("budget not found: %s", id)
+		}
+		return json.Unmarshal(raw, &out)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *Store) DeleteBudget(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucketBudgets))
+		if bk.Get([]byte(id)) == nil {
+			return fmt.Errorf("budget not found: %s", id)
+		}
+		return bk.Delete([]byte(id))
+	})
+}
+
+func (s *Store) ListBudgets() ([]domain.Budget, error) {
+	var res []domain.Budget
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucketBudgets))
+		return bk.ForEach(func(k, v []byte) error {
+			var b domain.Budget
+			if err := json.Unmarshal(v, &b); err != nil {
+				return err
+			}
+			res = append(res, b)
+			return nil
+		})
+	})
+	return res, err
+}
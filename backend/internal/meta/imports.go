@@ -0,0 +1,54 @@
+package meta
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"bankdash/backend/internal/domain"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func (s *Store) RecordImportBatch(b domain.ImportBatch) error {
+	return s.putJSON(bucketImportBatches, b.ID, b)
+}
+
+func (s *Store) GetImportBatch(id string) (*domain.ImportBatch, error) {
+	var out domain.ImportBatch
+	err := s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket([]byte(bucketImportBatches)).Get([]byte(id))
+		if raw == nil {
+			return fmt.Errorf("import batch not found: %s", id)
+		}
+		return json.Unmarshal(raw, &out)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListImportBatches returns batches newest first, optionally only those
+// parsed with templateID.
+func (s *Store) ListImportBatches(templateID string, limit int) ([]domain.ImportBatch, error) {
+	var res []domain.ImportBatch
+	err := s.forEachJSON(bucketImportBatches, func(v []byte) error {
+		var b domain.ImportBatch
+		if err := json.Unmarshal(v, &b); err != nil {
+			return err
+		}
+		if templateID == "" || b.TemplateID == templateID {
+			res = append(res, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].ImportedAt.After(res[j].ImportedAt) })
+	if limit > 0 && len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
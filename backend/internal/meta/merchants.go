@@ -0,0 +1,57 @@
+package meta
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"bankdash/backend/internal/domain"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// aliases are keyed by their upper-cased match string
+func aliasKey(match string) []byte {
+	return []byte(strings.ToUpper(strings.Join(strings.Fields(match), " ")))
+}
+
+func (s *Store) UpsertMerchantAlias(a domain.MerchantAlias) error {
+	if strings.TrimSpace(a.Match) == "" || strings.TrimSpace(a.Merchant) == "" {
+		return fmt.Errorf("alias match and merchant are required")
+	}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucketMerchantAliases))
+		return bk.Put(aliasKey(a.Match), b)
+	})
+}
+
+func (s *Store) DeleteMerchantAlias(match string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucketMerchantAliases))
+		k := aliasKey(match)
+		if bk.Get(k) == nil {
+			return fmt.Errorf("merchant alias not found: %s", match)
+		}
+		return bk.Delete(k)
+	})
+}
+
+func (s *Store) ListMerchantAliases() ([]domain.MerchantAlias, error) {
+	var res []domain.MerchantAlias
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucketMerchantAliases))
+		return bk.ForEach(func(k, v []byte) error {
+			var a domain.MerchantAlias
+			if err := json.Unmarshal(v, &a); err != nil {
+				return err
+			}
+			res = append(res, a)
+			return nil
+		})
+	})
+	return res, err
+}
@@ -0,0 +1,265 @@
+package meta
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/search"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// The full-text index keeps one key per term and booking,
+// "<term>\x00<tx_uid>" -> encoded postings, so exact and prefix lookups are
+// a cursor seek. search_docs holds the booking itself and its terms, which
+// are needed to drop stale postings when a booking is indexed again.
+
+type searchDoc struct {
+	Tx    domain.Transaction `json:"tx"`
+	Terms []string           `json:"terms"`
+}
+
+// phrases rank above the same words scattered over the booking
+const phraseBoost = 2
+
+// IndexTransactions adds or replaces txs in the full-text index.
+func (s *Store) IndexTransactions(txs []domain.Transaction) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		docs := tx.Bucket([]byte(bucketSearchDocs))
+		terms := tx.Bucket([]byte(bucketSearchTerms))
+		for _, t := range txs {
+			if t.TxUID == "" {
+				continue
+			}
+			uid := []byte(t.TxUID)
+			if err := unindex(docs, terms, t.TxUID); err != nil {
+				return err
+			}
+
+			doc := searchDoc{Tx: t}
+			for term, ps := range search.Terms(t) {
+				doc.Terms = append(doc.Terms, term)
+				if err := terms.Put(termKey(term, t.TxUID), search.EncodePostings(ps)); err != nil {
+					return err
+				}
+			}
+			sort.Strings(doc.Terms)
+			b, err := json.Marshal(doc)
+			if err != nil {
+				return err
+			}
+			if err := docs.Put(uid, b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveFromIndex drops bookings that no longer exist, e.g. replaced
+// pending ones.
+func (s *Store) RemoveFromIndex(uids []string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		docs := tx.Bucket([]byte(bucketSearchDocs))
+		terms := tx.Bucket([]byte(bucketSearchTerms))
+		for _, uid := range uids {
+			if err := unindex(docs, terms, uid); err != nil {
+				return err
+			}
+			if err := docs.Delete([]byte(uid)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// unindex deletes the postings of uid; its doc is left to the caller.
+func unindex(docs, terms *bolt.Bucket, uid string) error {
+	raw := docs.Get([]byte(uid))
+	if raw == nil {
+		return nil
+	}
+	var old searchDoc
+	if err := json.Unmarshal(raw, &old); err != nil {
+		return err
+	}
+	for _, term := range old.Terms {
+		if err := terms.Delete(termKey(term, uid)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Search returns the bookings matching every clause of q, best first, and
+// the total number of matches. accountIDs narrows the result when set.
+func (s *Store) Search(q string, accountIDs []string, limit int) ([]domain.SearchHit, int, error) {
+	clauses := search.Parse(q)
+	if len(clauses) == 0 {
+		return []domain.SearchHit{}, 0, nil
+	}
+
+	var hits []domain.SearchHit
+	err := s.db.View(func(tx *bolt.Tx) error {
+		docs := tx.Bucket([]byte(bucketSearchDocs))
+		terms := tx.Bucket([]byte(bucketSearchTerms))
+		n := docs.Stats().KeyN
+
+		var scores map[string]float64
+		for _, c := range clauses {
+			got, err := matchClause(terms, c, n)
+			if err != nil {
+				return err
+			}
+			if scores == nil {
+				scores = got
+			} else {
+				for uid, sc := range scores {
+					if v, ok := got[uid]; ok {
+						scores[uid] = sc + v
+					} else {
+						delete(scores, uid)
+					}
+				}
+			}
+			if len(scores) == 0 {
+				return nil
+			}
+		}
+
+		for uid, sc := range scores {
+			var doc searchDoc
+			raw := docs.Get([]byte(uid))
+			if raw == nil {
+				continue
+			}
+			if err := json.Unmarshal(raw, &doc); err != nil {
+				return err
+			}
+			if len(accountIDs) > 0 && !containsString(accountIDs, doc.Tx.AccountID) {
+				continue
+			}
+			hits = append(hits, domain.SearchHit{Score: sc, Transaction: doc.Tx})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if !hits[i].BookingDate.Equal(hits[j].BookingDate) {
+			return hits[i].BookingDate.After(hits[j].BookingDate)
+		}
+		return hits[i].TxUID < hits[j].TxUID
+	})
+	total := len(hits)
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	if hits == nil {
+		hits = []domain.SearchHit{}
+	}
+	return hits, total, nil
+}
+
+// matchClause scores every booking matching c.
+func matchClause(terms *bolt.Bucket, c search.Clause, docs int) (map[string]float64, error) {
+	if len(c.Terms) == 1 {
+		out := map[string]float64{}
+		err := scanTerm(terms, c.Terms[0], c.Prefix, func(_ string, byUID map[string][]search.Posting) {
+			for uid, ps := range byUID {
+				out[uid] += search.Score(ps, len(byUID), docs)
+			}
+		})
+		return out, err
+	}
+
+	// phrase: all words in one field, in order
+	per := make([]map[string][]search.Posting, len(c.Terms))
+	for i, term := range c.Terms {
+		err := scanTerm(terms, term, false, func(_ string, byUID map[string][]search.Posting) { per[i] = byUID })
+		if err != nil {
+			return nil, err
+		}
+		if len(per[i]) == 0 {
+			return nil, nil
+		}
+	}
+	out := map[string]float64{}
+	for uid := range per[0] {
+		lists := make([][]search.Posting, len(per))
+		complete := true
+		for i := range per {
+			if lists[i] = per[i][uid]; lists[i] == nil {
+				complete = false
+				break
+			}
+		}
+		if !complete || !search.PhraseAt(lists) {
+			continue
+		}
+		var sc float64
+		for i := range per {
+			sc += search.Score(lists[i], len(per[i]), docs)
+		}
+		out[uid] = sc * phraseBoost
+	}
+	return out, nil
+}
+
+// scanTerm calls fn once per index term equal to term (or starting with it,
+// for prefix lookups) with that term's postings by tx_uid.
+func scanTerm(terms *bolt.Bucket, term string, prefix bool, fn func(term string, byUID map[string][]search.Posting)) error {
+	seek := []byte(term)
+	if !prefix {
+		seek = append(seek, 0)
+	}
+	cur := terms.Cursor()
+	var (
+		curTerm string
+		byUID   map[string][]search.Posting
+	)
+	for k, v := cur.Seek(seek); k != nil && bytes.HasPrefix(k, seek); k, v = cur.Next() {
+		i := bytes.IndexByte(k, 0)
+		if i < 0 {
+			continue
+		}
+		t, uid := string(k[:i]), string(k[i+1:])
+		if t != curTerm && byUID != nil {
+			fn(curTerm, byUID)
+			byUID = nil
+		}
+		if byUID == nil {
+			curTerm, byUID = t, map[string][]search.Posting{}
+		}
+		ps, err := search.DecodePostings(v)
+		if err != nil {
+			return err
+		}
+		byUID[uid] = ps
+	}
+	if byUID != nil {
+		fn(curTerm, byUID)
+	}
+	return nil
+}
+
+func termKey(term, uid string) []byte {
+	return []byte(term + "\x00" + uid)
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
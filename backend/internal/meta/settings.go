@@ -0,0 +1,30 @@
+package meta
+
+import (
+	"encoding/json"
+
+	"bankdash/backend/internal/domain"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// singletons in the settings bucket
+const settingsDatev = "datev"
+
+// GetDatevSettings returns the stored settings, or zero values before the
+// first save.
+func (s *Store) GetDatevSettings() (domain.DatevSettings, error) {
+	var out domain.DatevSettings
+	err := s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket([]byte(bucketSettings)).Get([]byte(settingsDatev))
+		if raw == nil {
+			return nil
+		}
+		return json.Unmarshal(raw, &out)
+	})
+	return out, err
+}
+
+func (s *Store) PutDatevSettings(d domain.DatevSettings) error {
+	return s.putJSON(bucketSettings, settingsDatev, d)
+}
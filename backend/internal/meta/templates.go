@@ -0,0 +1,250 @@
+package meta
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/template"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Templates keep their full history: bucketTemplates holds the current
+// revision, bucketTemplateRevs every revision ever stored under
+// "<id>\x00<big-endian revision>". Deleting a template keeps its history,
+// so import batches can still be traced to the revision they used.
+
+// UpsertTemplate validates t and stores it as the next revision of its id.
+// Validation failures are *template.ValidationError.
+func (s *Store) UpsertTemplate(t domain.BankTemplate) error {
+	if err := template.Validate(t); err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		_, err := putTemplateRevision(tx, t)
+		return err
+	})
+}
+
+func putTemplateRevision(tx *bolt.Tx, t domain.BankTemplate) (domain.BankTemplate, error) {
+	revs := tx.Bucket([]byte(bucketTemplateRevs))
+	t.Revision = lastTemplateRevision(revs, t.ID) + 1
+	t.UpdatedAt = time.Now().UTC()
+	b, err := json.Marshal(t)
+	if err != nil {
+		return t, err
+	}
+	if err := revs.Put(templateRevKey(t.ID, t.Revision), b); err != nil {
+		return t, err
+	}
+	return t, tx.Bucket([]byte(bucketTemplates)).Put([]byte(t.ID), b)
+}
+
+func (s *Store) GetTemplate(id string) (*domain.BankTemplate, error) {
+	var out domain.BankTemplate
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucketTemplates))
+		raw := bk.Get([]byte(id))
+		if raw == nil {
+			return fmt.Errorf("template not found: %s", id)
+		}
+		var err error
+		out, err = decodeTemplate(raw)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *Store) ListTemplates() ([]domain.BankTemplate, error) {
+	var res []domain.BankTemplate
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucketTemplates))
+		return bk.ForEach(func(k, v []byte) error {
+			t, err := decodeTemplate(v)
+			if err != nil {
+				return err
+			}
+			res = append(res, t)
+			return nil
+		})
+	})
+	return res, err
+}
+
+// DeleteTemplate removes the current revision; the history stays.
+func (s *Store) DeleteTemplate(id string) error {
+	return s.deleteKey(bucketTemplates, id, "template")
+}
+
+// ListTemplateRevisions returns every stored revision of id, oldest first.
+func (s *Store) ListTemplateRevisions(id string) ([]domain.BankTemplate, error) {
+	var res []domain.BankTemplate
+	err := s.db.View(func(tx *bolt.Tx) error {
+		prefix := templateRevPrefix(id)
+		c := tx.Bucket([]byte(bucketTemplateRevs)).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			t, err := decodeTemplate(v)
+			if err != nil {
+				return err
+			}
+			res = append(res, t)
+		}
+		if len(res) == 0 {
+			return fmt.Errorf("template not found: %s", id)
+		}
+		return nil
+	})
+	return res, err
+}
+
+func (s *Store) GetTemplateRevision(id string, rev int) (*domain.BankTemplate, error) {
+	var out domain.BankTemplate
+	err := s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket([]byte(bucketTemplateRevs)).Get(templateRevKey(id, rev))
+		if raw == nil {
+			return fmt.Errorf("template revision not found: %s@%d", id, rev)
+		}
+		var err error
+		out, err = decodeTemplate(raw)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RollbackTemplate makes the content of revision rev current again, as a new
+// revision; it also restores deleted templates.
+func (s *Store) RollbackTemplate(id string, rev int) (*domain.BankTemplate, error) {
+	var out domain.BankTemplate
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		raw := tx.Bucket([]byte(bucketTemplateRevs)).Get(templateRevKey(id, rev))
+		if raw == nil {
+			return fmt.Errorf("template revision not found: %s@%d", id, rev)
+		}
+		t, err := decodeTemplate(raw)
+		if err != nil {
+			return err
+		}
+		out, err = putTemplateRevision(tx, t)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SeedTemplatesFromDir inserts templates whose id was never stored, so
+// edited or deliberately deleted templates are left alone. Invalid files are
+// skipped and reported together, each with its path.
+func (s *Store) SeedTemplatesFromDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(strings.ToLower(e.Name()), ".json") {
+			continue
+		}
+		full := filepath.Join(dir, e.Name())
+		raw, err := os.ReadFile(full)
+		if err != nil {
+			return err
+		}
+		t, err := decodeTemplate(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("template %s: %w", full, err))
+			continue
+		}
+		if err := template.Validate(t); err != nil {
+			errs = append(errs, fmt.Errorf("template %s: %w", full, err))
+			continue
+		}
+		// only insert if missing
+		if _, err := s.ListTemplateRevisions(t.ID); err == nil {
+			continue
+		}
+		if err := s.UpsertTemplate(t); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// migrateTemplates upgrades stored templates to the current schemaVersion
+// in place, and gives templates stored before revisions existed revision 1
+// so their content survives the first edit. Older revisions are upgraded
+// when read.
+func migrateTemplates(tx *bolt.Tx) error {
+	cur := tx.Bucket([]byte(bucketTemplates))
+	revs := tx.Bucket([]byte(bucketTemplateRevs))
+	var migrated, unrevised []domain.BankTemplate
+	err := cur.ForEach(func(k, v []byte) error {
+		t, changed, err := template.Migrate(v)
+		if err != nil {
+			return fmt.Errorf("template %s: %w", k, err)
+		}
+		switch {
+		case t.Revision == 0 || lastTemplateRevision(revs, t.ID) == 0:
+			unrevised = append(unrevised, t)
+		case changed:
+			migrated = append(migrated, t)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, t := range migrated {
+		b, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		if err := cur.Put([]byte(t.ID), b); err != nil {
+			return err
+		}
+	}
+	for _, t := range unrevised {
+		if _, err := putTemplateRevision(tx, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeTemplate reads a stored template of any schemaVersion.
+func decodeTemplate(raw []byte) (domain.BankTemplate, error) {
+	t, _, err := template.Migrate(raw)
+	return t, err
+}
+
+func lastTemplateRevision(revs *bolt.Bucket, id string) int {
+	prefix := templateRevPrefix(id)
+	c := revs.Cursor()
+	last := 0
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		last = int(binary.BigEndian.Uint32(k[len(prefix):]))
+	}
+	return last
+}
+
+func templateRevPrefix(id string) []byte { return []byte(id + "\x00") }
+
+// big-endian keeps revisions in numeric order under the cursor
+func templateRevKey(id string, rev int) []byte {
+	return binary.BigEndian.AppendUint32(templateRevPrefix(id), uint32(rev))
+}
@@ -0,0 +1,113 @@
+// Package pending pairs booked bookings with the pending versions of the
+// same payment that earlier exports contained.
+package pending
+
+import (
+	"strings"
+	"time"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/util"
+)
+
+// Matcher finds the pending booking a booked one settles: same account,
+// currency and amount, dated at most Window before and a day after the
+// booked one. Memo and date often change on settlement, so they only rank
+// candidates: same payee or merchant first, then the closest date.
+type Matcher struct {
+	window time.Duration
+}
+
+func NewMatcher(windowDays int) *Matcher {
+	return &Matcher{window: time.Duration(windowDays) * 24 * time.Hour}
+}
+
+// Window is how long a booking may stay pending.
+func (m *Matcher) Window() time.Duration { return m.window }
+
+// Match links booked entries of batch to the stored (already imported, same
+// account) pending booking they replace, and sets their ReplacesUID. Pending
+// rows of the batch are no candidates: next to a booked row of the same
+// amount in one file they are usually a second payment. Match returns the
+// indexes of stored pending entries to delete, and of batch entries to drop
+// because they are pending and an earlier import already replaced them.
+func (m *Matcher) Match(batch, stored []domain.Transaction) (replaced, drop []int) {
+	storedByUID := map[string]int{}
+	gone := map[string]bool{}
+	for si, tx := range stored {
+		storedByUID[tx.TxUID] = si
+		if tx.ReplacesUID != "" {
+			gone[tx.ReplacesUID] = true
+		}
+	}
+
+	var cands []*domain.Transaction
+	for si := range stored {
+		if stored[si].Status == domain.TxStatusPending {
+			cands = append(cands, &stored[si])
+		}
+	}
+
+	for bi := range batch {
+		a := &batch[bi]
+		if a.Status == domain.TxStatusPending {
+			continue
+		}
+		// a re-import keeps the link made the first time and must not
+		// claim another pending booking
+		if si, ok := storedByUID[a.TxUID]; ok {
+			a.ReplacesUID = stored[si].ReplacesUID
+			continue
+		}
+		var best *domain.Transaction
+		for _, c := range cands {
+			if gone[c.TxUID] || !m.settles(a, c) {
+				continue
+			}
+			if best == nil || better(a, c, best) {
+				best = c
+			}
+		}
+		if best != nil {
+			a.ReplacesUID = best.TxUID
+			gone[best.TxUID] = true
+		}
+	}
+
+	for si, tx := range stored {
+		if tx.Status == domain.TxStatusPending && gone[tx.TxUID] {
+			replaced = append(replaced, si)
+		}
+	}
+	for bi, tx := range batch {
+		if tx.Status == domain.TxStatusPending && gone[tx.TxUID] {
+			drop = append(drop, bi)
+		}
+	}
+	return replaced, drop
+}
+
+// settles reports whether booked a can be the settlement of pending p.
+func (m *Matcher) settles(a, p *domain.Transaction) bool {
+	if a.AccountID != p.AccountID || a.AmountCents != p.AmountCents || a.Currency != p.Currency {
+		return false
+	}
+	d := a.BookingDate.Sub(p.BookingDate)
+	return d >= -24*time.Hour && d <= m.window
+}
+
+// better reports whether c is a closer match for a than cur.
+func better(a, c, cur *domain.Transaction) bool {
+	if sc, scur := samePayee(a, c), samePayee(a, cur); sc != scur {
+		return sc
+	}
+	return util.AbsDuration(a.BookingDate.Sub(c.BookingDate)) < util.AbsDuration(a.BookingDate.Sub(cur.BookingDate))
+}
+
+func samePayee(a, b *domain.Transaction) bool {
+	if a.Merchant != "" && strings.EqualFold(a.Merchant, b.Merchant) {
+		return true
+	}
+	pa, pb := util.NormName(a.Payee), util.NormName(b.Payee)
+	return pa != "" && pa == pb
+}
@@ -0,0 +1,125 @@
+package pending
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"bankdash/backend/internal/domain"
+)
+
+func day(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+
+func booked(uid string, d int, cents int64, payee string) domain.Transaction {
+	return domain.Transaction{TxUID: uid, AccountID: "main", Currency: "EUR", AmountCents: cents,
+		BookingDate: day(d), Payee: payee, Status: domain.TxStatusBooked}
+}
+
+func pendingTx(uid string, d int, cents int64, payee string) domain.Transaction {
+	tx := booked(uid, d, cents, payee)
+	tx.Status = domain.TxStatusPending
+	return tx
+}
+
+func withReplaces(tx domain.Transaction, uid string) domain.Transaction {
+	tx.ReplacesUID = uid
+	return tx
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		batch    []domain.Transaction
+		stored   []domain.Transaction
+		replaces []string // ReplacesUID per batch entry afterwards
+		replaced []int
+		drop     []int
+	}{
+		{
+			name:     "settled with another memo and date",
+			batch:    []domain.Transaction{{TxUID: "b", AccountID: "main", Currency: "EUR", AmountCents: -2895, BookingDate: day(5), Payee: "REWE", Memo: "REWE SAGT DANKE 0815"}},
+			stored:   []domain.Transaction{{TxUID: "p", AccountID: "main", Currency: "EUR", AmountCents: -2895, BookingDate: day(2), Payee: "REWE", Memo: "Kartenzahlung", Status: domain.TxStatusPending}},
+			replaces: []string{"p"},
+			replaced: []int{0},
+		},
+		{
+			name:     "booked a day before the pending date",
+			batch:    []domain.Transaction{booked("b", 2, -1000, "Shell")},
+			stored:   []domain.Transaction{pendingTx("p", 3, -1000, "Shell")},
+			replaces: []string{"p"},
+			replaced: []int{0},
+		},
+		{
+			name:     "pending too old",
+			batch:    []domain.Transaction{booked("b", 20, -1000, "Shell")},
+			stored:   []domain.Transaction{pendingTx("p", 3, -1000, "Shell")},
+			replaces: []string{""},
+		},
+		{
+			name:     "other amount, currency or account",
+			batch:    []domain.Transaction{booked("b", 5, -1000, "Shell")},
+			stored:   []domain.Transaction{pendingTx("p1", 3, -1001, "Shell"), {TxUID: "p2", AccountID: "main", Currency: "USD", AmountCents: -1000, BookingDate: day(3), Status: domain.TxStatusPending}, {TxUID: "p3", AccountID: "card", Currency: "EUR", AmountCents: -1000, BookingDate: day(3), Status: domain.TxStatusPending}},
+			replaces: []string{""},
+		},
+		{
+			name: "old export re-imported after settlement",
+			// the pending row comes back, but its booked version replaced it
+			batch:    []domain.Transaction{pendingTx("p", 2, -2895, "REWE"), booked("other", 2, -500, "Bäcker")},
+			stored:   []domain.Transaction{withReplaces(booked("b", 5, -2895, "REWE"), "p")},
+			replaces: []string{"", ""},
+			drop:     []int{0},
+		},
+		{
+			name: "re-imported booked entry keeps its link",
+			// the pending p2 would settle it too, but b was linked to p before
+			batch:    []domain.Transaction{booked("b", 5, -2895, "REWE")},
+			stored:   []domain.Transaction{withReplaces(booked("b", 5, -2895, "REWE"), "p"), pendingTx("p2", 4, -2895, "REWE")},
+			replaces: []string{"p"},
+		},
+		{
+			name:     "same payee wins over the closer date",
+			batch:    []domain.Transaction{booked("b", 5, -1999, "Amazon")},
+			stored:   []domain.Transaction{pendingTx("near", 4, -1999, "Zalando"), pendingTx("payee", 1, -1999, " amazon ")},
+			replaces: []string{"payee"},
+			replaced: []int{1},
+		},
+		{
+			name:     "then the closer date",
+			batch:    []domain.Transaction{booked("b", 5, -1999, "Amazon")},
+			stored:   []domain.Transaction{pendingTx("far", 1, -1999, "Amazon"), pendingTx("near", 4, -1999, "Amazon")},
+			replaces: []string{"near"},
+			replaced: []int{1},
+		},
+		{
+			name:     "two booked, two pending: each pending settles once",
+			batch:    []domain.Transaction{booked("b1", 5, -450, "Café"), booked("b2", 5, -450, "Café")},
+			stored:   []domain.Transaction{pendingTx("p1", 4, -450, "Café"), pendingTx("p2", 3, -450, "Café")},
+			replaces: []string{"p1", "p2"},
+			replaced: []int{0, 1},
+		},
+		{
+			name: "pending and booked in the same file stay two payments",
+			// two coffees, one already booked, the other still pending
+			batch:    []domain.Transaction{booked("b", 5, -450, "Café"), pendingTx("p", 5, -450, "Café")},
+			replaces: []string{"", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replaced, drop := NewMatcher(10).Match(tt.batch, tt.stored)
+			var replaces []string
+			for _, tx := range tt.batch {
+				replaces = append(replaces, tx.ReplacesUID)
+			}
+			if !slices.Equal(replaces, tt.replaces) {
+				t.Errorf("ReplacesUID = %q, want %q", replaces, tt.replaces)
+			}
+			if !slices.Equal(replaced, tt.replaced) {
+				t.Errorf("replaced = %v, want %v", replaced, tt.replaced)
+			}
+			if !slices.Equal(drop, tt.drop) {
+				t.Errorf("drop = %v, want %v", drop, tt.drop)
+			}
+		})
+	}
+}
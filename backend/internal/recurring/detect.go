@@ -0,0 +1,188 @@
+package recurring
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"bankdash/backend/internal/domain"
+)
+
+type interval struct {
+	name     string
+	days     float64 // nominal gap
+	tol      float64 // accepted deviation in days
+	minCount int
+	next     func(time.Time) time.Time
+}
+
+var intervals = []interval{
+	{"weekly", 7, 2, 4, func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }},
+	{"monthly", 30.4, 4, 3, func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+	{"quarterly", 91.3, 10, 3, func(t time.Time) time.Time { return t.AddDate(0, 3, 0) }},
+	{"yearly", 365.25, 20, 2, func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+}
+
+// Next returns the due date after t for a series interval name, or the zero
+// time for an unknown interval.
+func Next(intervalName string, t time.Time) time.Time {
+	for _, iv := range intervals {
+		if iv.name == intervalName {
+			return iv.next(t)
+		}
+	}
+	return time.Time{}
+}
+
+const (
+	// amounts within this relative distance of the previous one stay in the
+	// same series, so gradual price increases don't split it
+	amountTolerance = 0.25
+	// share of gaps that must fit the interval
+	minRegularity = 0.7
+)
+
+// Detect finds recurring series in txs. now decides whether a series is
+// still active. Transfers between own accounts are ignored.
+func Detect(txs []domain.Transaction, now time.Time) []domain.RecurringSeries {
+	groups := map[string][]domain.Transaction{}
+	var keys []string
+	for _, tx := range txs {
+		if tx.CategoryID == domain.CategoryTransfer || tx.AmountCents == 0 {
+			continue
+		}
+		k := strings.Join([]string{tx.AccountID, merchantKey(tx), tx.Direction, tx.Currency}, "|")
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], tx)
+	}
+	sort.Strings(keys)
+
+	var out []domain.RecurringSeries
+	for _, k := range keys {
+		g := groups[k]
+		sort.SliceStable(g, func(i, j int) bool { return g[i].BookingDate.Before(g[j].BookingDate) })
+		for _, c := range clusterByAmount(g) {
+			if s, ok := detectSeries(c, now); ok {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+func merchantKey(tx domain.Transaction) string {
+	if tx.Merchant != "" {
+		return strings.ToLower(tx.Merchant)
+	}
+	return strings.ToLower(strings.Join(strings.Fields(tx.Payee), " "))
+}
+
+// clusterByAmount splits one merchant's (date-sorted) bookings into amount
+// bands, e.g. two different subscriptions with the same merchant.
+func clusterByAmount(g []domain.Transaction) [][]domain.Transaction {
+	var clusters [][]domain.Transaction
+	for _, tx := range g {
+		placed := false
+		for i, c := range clusters {
+			if near(c[len(c)-1].AmountCents, tx.AmountCents) {
+				clusters[i] = append(c, tx)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			clusters = append(clusters, []domain.Transaction{tx})
+		}
+	}
+	return clusters
+}
+
+func detectSeries(c []domain.Transaction, now time.Time) (domain.RecurringSeries, bool) {
+	if len(c) < 2 {
+		return domain.RecurringSeries{}, false
+	}
+	gaps := make([]float64, 0, len(c)-1)
+	for i := 1; i < len(c); i++ {
+		gaps = append(gaps, c[i].BookingDate.Sub(c[i-1].BookingDate).Hours()/24)
+	}
+	med := median(gaps)
+
+	for _, iv := range intervals {
+		if len(c) < iv.minCount || abs(med-iv.days) > iv.tol {
+			continue
+		}
+		fit := 0
+		for _, g := range gaps {
+			if abs(g-iv.days) <= iv.tol {
+				fit++
+			}
+		}
+		if float64(fit)/float64(len(gaps)) < minRegularity {
+			continue
+		}
+		return buildSeries(c, iv, now), true
+	}
+	return domain.RecurringSeries{}, false
+}
+
+func buildSeries(c []domain.Transaction, iv interval, now time.Time) domain.RecurringSeries {
+	first, last := c[0], c[len(c)-1]
+	s := domain.RecurringSeries{
+		AccountID:       last.AccountID,
+		Merchant:        last.Merchant,
+		Currency:        last.Currency,
+		Direction:       last.Direction,
+		Interval:        iv.name,
+		Count:           len(c),
+		LastAmountCents: last.AmountCents,
+		FirstDate:       first.BookingDate,
+		LastDate:        last.BookingDate,
+		NextExpected:    iv.next(last.BookingDate),
+	}
+	if s.Merchant == "" {
+		s.Merchant = last.Payee
+	}
+	var sum int64
+	for i, tx := range c {
+		sum += tx.AmountCents
+		s.TxUIDs = append(s.TxUIDs, tx.TxUID)
+		if i > 0 && tx.AmountCents != c[i-1].AmountCents {
+			s.PriceChanges = append(s.PriceChanges, domain.PriceChange{
+				Date:      tx.BookingDate,
+				FromCents: c[i-1].AmountCents,
+				ToCents:   tx.AmountCents,
+			})
+		}
+	}
+	s.AverageAmountCents = sum / int64(len(c))
+	grace := time.Duration(iv.tol*24) * time.Hour
+	s.Active = !now.After(s.NextExpected.Add(grace))
+	return s
+}
+
+func near(a, b int64) bool {
+	fa, fb := abs(float64(a)), abs(float64(b))
+	if fa == 0 || fb == 0 {
+		return fa == fb
+	}
+	return abs(fa-fb)/max(fa, fb) <= amountTolerance
+}
+
+func median(v []float64) float64 {
+	s := append([]float64(nil), v...)
+	sort.Float64s(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,168 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"bankdash/backend/internal/domain"
+)
+
+// Options select what Build groups by and over which dates.
+type Options struct {
+	GroupBy  string
+	Interval string
+	From     time.Time // calendar date, widened to its period start
+	To       time.Time // exclusive
+}
+
+func Validate(o Options) error {
+	switch o.GroupBy {
+	case domain.ReportByCategory, domain.ReportByMerchant, domain.ReportByAccount, domain.ReportByDirection:
+	default:
+		return fmt.Errorf("unknown group_by: %q", o.GroupBy)
+	}
+	switch o.Interval {
+	case domain.IntervalDay, domain.IntervalWeek, domain.IntervalMonth, domain.IntervalYear:
+	default:
+		return fmt.Errorf("unknown interval: %q", o.Interval)
+	}
+	if !o.From.Before(o.To) {
+		return fmt.Errorf("from must be before to")
+	}
+	return nil
+}
+
+// LookbackStart is the earliest booking date Build needs for o, including
+// the comparison periods.
+func LookbackStart(o Options) time.Time {
+	// a year back, plus up to a week for ISO week alignment
+	return PeriodStart(o.Interval, o.From).AddDate(-1, 0, -7)
+}
+
+// PeriodStart truncates the calendar date t to its interval.
+func PeriodStart(interval string, t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch interval {
+	case domain.IntervalWeek:
+		day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case domain.IntervalMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	case domain.IntervalYear:
+		return time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// Next returns the start of the period after the one starting at start.
+func Next(interval string, start time.Time) time.Time {
+	switch interval {
+	case domain.IntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case domain.IntervalMonth:
+		return start.AddDate(0, 1, 0)
+	case domain.IntervalYear:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+func previous(interval string, start time.Time) time.Time {
+	switch interval {
+	case domain.IntervalWeek:
+		return start.AddDate(0, 0, -7)
+	case domain.IntervalMonth:
+		return start.AddDate(0, -1, 0)
+	case domain.IntervalYear:
+		return start.AddDate(-1, 0, 0)
+	default:
+		return start.AddDate(0, 0, -1)
+	}
+}
+
+// lastYear maps a period start to the matching period a year earlier; for
+// weeks that is the week containing the same date.
+func lastYear(interval string, start time.Time) time.Time {
+	return PeriodStart(interval, start.AddDate(-1, 0, 0))
+}
+
+type cell struct {
+	period time.Time
+	key    string
+	cur    string
+}
+
+// Build sums txs by booking date. Rows cover the periods overlapping
+// [o.From, o.To) in which the group had bookings, ordered by period, then
+// by key.
+func Build(txs []domain.Transaction, o Options) domain.Report {
+	sums := map[cell]*domain.ReportTotals{}
+	for _, tx := range txs {
+		c := cell{PeriodStart(o.Interval, tx.BookingDate), keyOf(o.GroupBy, tx), tx.Currency}
+		t := sums[c]
+		if t == nil {
+			t = &domain.ReportTotals{}
+			sums[c] = t
+		}
+		if tx.AmountCents >= 0 {
+			t.InCents += tx.AmountCents
+		} else {
+			t.OutCents -= tx.AmountCents
+		}
+		t.NetCents += tx.AmountCents
+		t.Count++
+	}
+
+	first := PeriodStart(o.Interval, o.From)
+	rep := domain.Report{GroupBy: o.GroupBy, Interval: o.Interval, From: first, To: o.To, Rows: []domain.ReportRow{}}
+	for c, t := range sums {
+		if c.period.Before(first) || !c.period.Before(o.To) {
+			continue
+		}
+		row := domain.ReportRow{
+			PeriodStart: c.period,
+			PeriodEnd:   Next(o.Interval, c.period),
+			Key:         c.key,
+			Currency:    c.cur,
+			Totals:      *t,
+		}
+		if p := sums[cell{previous(o.Interval, c.period), c.key, c.cur}]; p != nil {
+			row.Previous = *p
+		}
+		if p := sums[cell{lastYear(o.Interval, c.period), c.key, c.cur}]; p != nil {
+			row.LastYear = *p
+		}
+		rep.Rows = append(rep.Rows, row)
+	}
+	sort.Slice(rep.Rows, func(i, j int) bool {
+		a, b := rep.Rows[i], rep.Rows[j]
+		if !a.PeriodStart.Equal(b.PeriodStart) {
+			return a.PeriodStart.Before(b.PeriodStart)
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Currency < b.Currency
+	})
+	return rep
+}
+
+func keyOf(groupBy string, tx domain.Transaction) string {
+	switch groupBy {
+	case domain.ReportByMerchant:
+		if tx.Merchant != "" {
+			return tx.Merchant
+		}
+		return strings.Join(strings.Fields(tx.Payee), " ")
+	case domain.ReportByAccount:
+		return tx.AccountID
+	case domain.ReportByDirection:
+		return tx.Direction
+	default:
+		return tx.CategoryID
+	}
+}
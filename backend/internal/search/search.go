@@ -0,0 +1,172 @@
+// Package search tokenizes booking texts for the full-text index in the meta
+// store and parses queries against it. Storage lives in meta/search.go.
+package search
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"unicode"
+
+	"bankdash/backend/internal/domain"
+)
+
+// Field identifies where in a booking a token was found.
+type Field uint8
+
+const (
+	FieldPayee Field = iota
+	FieldMerchant
+	FieldReference
+	FieldMemo
+)
+
+// a payee hit says more than the same word somewhere in a long memo
+var fieldWeight = map[Field]float64{
+	FieldPayee:     2,
+	FieldMerchant:  2,
+	FieldReference: 1.5,
+	FieldMemo:      1,
+}
+
+// Posting is one occurrence of a term: field and token position within it.
+type Posting struct {
+	Field Field
+	Pos   uint32
+}
+
+var folder = strings.NewReplacer(
+	"ä", "a", "ö", "o", "ü", "u", "ß", "ss",
+	// Müller, Mueller and Muller should all find each other
+	"ae", "a", "oe", "o", "ue", "u",
+	"à", "a", "á", "a", "â", "a", "å", "a",
+	"ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "ø", "o",
+	"ù", "u", "ú", "u", "û", "u",
+)
+
+// Fold lower-cases s and folds umlauts, their ae/oe/ue spellings and common
+// accents, the same way for documents and queries.
+func Fold(s string) string {
+	return folder.Replace(strings.ToLower(s))
+}
+
+// Tokens splits s into folded words; anything but letters and digits
+// separates them, so "302-1234567-7654321" becomes three tokens that a phrase
+// query finds again.
+func Tokens(s string) []string {
+	return strings.FieldsFunc(Fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Terms returns the postings of every term in tx.
+func Terms(tx domain.Transaction) map[string][]Posting {
+	out := map[string][]Posting{}
+	for f, text := range map[Field]string{
+		FieldPayee:     tx.Payee,
+		FieldMerchant:  tx.Merchant,
+		FieldReference: tx.Reference,
+		FieldMemo:      tx.Memo,
+	} {
+		for i, tok := range Tokens(text) {
+			out[tok] = append(out[tok], Posting{Field: f, Pos: uint32(i)})
+		}
+	}
+	return out
+}
+
+// EncodePostings packs postings as (field byte, uvarint position) pairs.
+func EncodePostings(ps []Posting) []byte {
+	b := make([]byte, 0, len(ps)*2)
+	for _, p := range ps {
+		b = append(b, byte(p.Field))
+		b = binary.AppendUvarint(b, uint64(p.Pos))
+	}
+	return b
+}
+
+func DecodePostings(b []byte) ([]Posting, error) {
+	var out []Posting
+	for len(b) > 0 {
+		f := Field(b[0])
+		pos, n := binary.Uvarint(b[1:])
+		if n <= 0 {
+			return nil, errors.New("corrupt search posting")
+		}
+		out = append(out, Posting{Field: f, Pos: uint32(pos)})
+		b = b[1+n:]
+	}
+	return out, nil
+}
+
+// Clause is one part of a query. Every clause has to match.
+type Clause struct {
+	Terms  []string // a single term, or the words of a "quoted phrase"
+	Prefix bool     // term ended in *; never set for phrases
+}
+
+// Parse splits q into clauses: bare words, "quoted phrases" and prefix
+// words ending in *. Punctuation inside a bare word turns it into a phrase,
+// so an order number can be pasted as is.
+func Parse(q string) []Clause {
+	var out []Clause
+	for i, part := range strings.Split(q, `"`) {
+		if i%2 == 1 {
+			if toks := Tokens(part); len(toks) > 0 {
+				out = append(out, Clause{Terms: toks})
+			}
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			prefix := strings.HasSuffix(word, "*")
+			toks := Tokens(strings.TrimRight(word, "*"))
+			switch {
+			case len(toks) == 0:
+			case len(toks) == 1:
+				out = append(out, Clause{Terms: toks, Prefix: prefix})
+			default:
+				out = append(out, Clause{Terms: toks})
+			}
+		}
+	}
+	return out
+}
+
+// Score ranks the occurrences of one term in a document with a BM25-like
+// saturation: repeating a word helps, but less and less.
+func Score(ps []Posting, df, docs int) float64 {
+	var tf float64
+	for _, p := range ps {
+		tf += fieldWeight[p.Field]
+	}
+	const k = 1.2
+	idf := math.Log(1 + (float64(docs)-float64(df)+0.5)/(float64(df)+0.5))
+	return idf * tf * (k + 1) / (tf + k)
+}
+
+// PhraseAt reports whether terms occur consecutively, given the postings of
+// each term in one document.
+func PhraseAt(postings [][]Posting) bool {
+	if len(postings) == 0 {
+		return false
+	}
+	for _, start := range postings[0] {
+		ok := true
+		for i := 1; i < len(postings) && ok; i++ {
+			ok = false
+			for _, p := range postings[i] {
+				if p.Field == start.Field && p.Pos == start.Pos+uint32(i) {
+					ok = true
+					break
+				}
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"bankdash/backend/internal/domain"
+)
+
+// migrations[i] upgrades a document from schemaVersion i+1 to i+2. They work
+// on the raw JSON so fields that no longer exist in domain.BankTemplate can
+// still be read. Append, never edit: stored and shared templates may be at
+// any older version.
+var migrations = []func(doc map[string]any) error{
+	migrateV1,
+}
+
+// Migrate decodes a template document of any known schemaVersion (documents
+// without one are version 1) and upgrades it to
+// domain.TemplateSchemaVersion. changed reports whether an upgrade ran.
+func Migrate(raw []byte) (t domain.BankTemplate, changed bool, err error) {
+	var doc map[string]any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return t, false, err
+	}
+	v := 1
+	if n, ok := doc["schemaVersion"].(float64); ok && n > 0 {
+		v = int(n)
+	}
+	if v > domain.TemplateSchemaVersion {
+		return t, false, fmt.Errorf("template schemaVersion %d is newer than this server supports (%d)", v, domain.TemplateSchemaVersion)
+	}
+	for ; v < domain.TemplateSchemaVersion; v++ {
+		if err := migrations[v-1](doc); err != nil {
+			return t, false, fmt.Errorf("migrate template from schemaVersion %d: %w", v, err)
+		}
+		doc["schemaVersion"] = v + 1
+		changed = true
+	}
+	if changed {
+		if raw, err = json.Marshal(doc); err != nil {
+			return t, false, err
+		}
+	}
+	err = json.Unmarshal(raw, &t)
+	return t, changed, err
+}
+
+// migrateV1 spells out what version 1 left implicit: type csv, ";" as
+// delimiter, and a tab written as the two characters `\t`.
+func migrateV1(doc map[string]any) error {
+	if s, _ := doc["type"].(string); s == "" {
+		doc["type"] = "csv"
+	}
+	csv, _ := doc["csv"].(map[string]any)
+	if csv == nil {
+		return nil
+	}
+	switch d, _ := csv["delimiter"].(string); d {
+	case "":
+		csv["delimiter"] = ";"
+	case `\t`:
+		csv["delimiter"] = "\t"
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package template
+
+import (
+	"strings"
+	"testing"
+
+	"bankdash/backend/internal/domain"
+)
+
+func TestMigrateV1(t *testing.T) {
+	tests := []struct {
+		name      string
+		doc       string
+		delimiter string
+	}{
+		{"empty delimiter", `{"id":"old","csv":{"delimiter":"","dateFormats":["02.01.2006"]}}`, ";"},
+		{"no delimiter", `{"id":"old","csv":{"dateFormats":["02.01.2006"]}}`, ";"},
+		{"escaped tab", `{"id":"old","csv":{"delimiter":"\\t","dateFormats":["02.01.2006"]}}`, "\t"},
+		{"comma kept", `{"id":"old","schemaVersion":1,"csv":{"delimiter":","}}`, ","},
+	}
+	for _, tt := range tests {
+		got, changed, err := Migrate([]byte(tt.doc))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !changed || got.SchemaVersion != domain.TemplateSchemaVersion {
+			t.Errorf("%s: changed %v, schemaVersion %d", tt.name, changed, got.SchemaVersion)
+		}
+		if got.Type != "csv" || got.CSV.Delimiter != tt.delimiter || got.ID != "old" {
+			t.Errorf("%s: type %q, delimiter %q, id %q", tt.name, got.Type, got.CSV.Delimiter, got.ID)
+		}
+	}
+}
+
+func TestMigrateCurrentUnchanged(t *testing.T) {
+	// already a real tab; nothing to upgrade
+	doc := `{"schemaVersion":2,"id":"tsv","type":"csv","csv":{"delimiter":"\t","dateFormats":["2006-01-02"],"decimal":"en"}}`
+	got, changed, err := Migrate([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Error("changed = true for a current document")
+	}
+	if got.SchemaVersion != 2 || got.CSV.Delimiter != "\t" || got.CSV.Decimal != "en" {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestMigrateRejectsNewerVersion(t *testing.T) {
+	doc := `{"schemaVersion":99,"id":"future","type":"csv"}`
+	_, _, err := Migrate([]byte(doc))
+	if err == nil || !strings.Contains(err.Error(), "newer than this server supports") {
+		t.Fatalf("Migrate() = %v, want a newer-version error", err)
+	}
+}
@@ -0,0 +1,9 @@
+package template
+
+import _ "embed"
+
+// Schema is the JSON Schema of domain.BankTemplate at
+// domain.TemplateSchemaVersion. Keep it in step with Validate.
+//
+//go:embed schema.json
+var Schema []byte
@@ -0,0 +1,250 @@
+// Package template checks bank templates before they are stored, so a bad
+// template fails on upsert instead of on the next import.
+package template
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/importer/rowfilter"
+	"bankdash/backend/internal/importer/transform"
+	"bankdash/backend/internal/util"
+)
+
+// FieldError names the offending field by its JSON path, e.g.
+// "csv.dateFormats[1]".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every problem found in one template.
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return "invalid template: " + strings.Join(parts, "; ")
+}
+
+var (
+	idRe       = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	noHdrCol   = regexp.MustCompile(`^col_[0-9]+$`)
+	currencyRe = regexp.MustCompile(`^[A-Z]{3}$`)
+	// a date whose day, month and year all differ, to catch layouts missing one
+	probeDate = time.Date(2031, 11, 27, 0, 0, 0, 0, time.UTC)
+)
+
+// Validate returns a *ValidationError, or nil when t can be imported with.
+func Validate(t domain.BankTemplate) error {
+	var errs []FieldError
+	add := func(field, format string, args ...any) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if t.SchemaVersion != domain.TemplateSchemaVersion {
+		add("schemaVersion", "must be %d (older documents go through Migrate)", domain.TemplateSchemaVersion)
+	}
+	if !idRe.MatchString(t.ID) {
+		add("id", "required; letters, digits, '.', '_' and '-' only")
+	}
+	if t.Type != "csv" {
+		add("type", "unsupported type %q (want csv)", t.Type)
+	}
+
+	c := t.CSV
+	switch {
+	case c.Delimiter == "", c.Delimiter == `\t`:
+	case utf8.RuneCountInString(c.Delimiter) != 1:
+		add("csv.delimiter", "must be a single character or \\t, got %q", c.Delimiter)
+	case strings.ContainsAny(c.Delimiter, "\"\r\n"):
+		add("csv.delimiter", "%q cannot be used as delimiter", c.Delimiter)
+	}
+	if c.SkipRows < 0 {
+		add("csv.skipRows", "must not be negative")
+	}
+	if !util.RoundingMode(c.Rounding).Valid() {
+		add("csv.rounding", "unknown mode %q (want halfUp, halfEven or down)", c.Rounding)
+	}
+	if c.Currency != "" && !currencyRe.MatchString(c.Currency) {
+		add("csv.currency", "must be an ISO 4217 code like EUR, got %q", c.Currency)
+	}
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			add("csv.timezone", "unknown IANA timezone %q", c.Timezone)
+		}
+	}
+	if c.HeaderSearch && !c.HasHeader {
+		add("csv.headerSearch", "needs hasHeader")
+	}
+
+	if len(c.DateFormats) == 0 {
+		add("csv.dateFormats", "at least one Go date layout is required, e.g. \"02.01.2006\"")
+	}
+	for i, f := range c.DateFormats {
+		if msg := checkLayout(f); msg != "" {
+			add(fmt.Sprintf("csv.dateFormats[%d]", i), "%s", msg)
+		}
+	}
+
+	if c.YearPivot < 0 || c.YearPivot > 99 {
+		add("csv.yearPivot", "must be between 0 and 99")
+	}
+	if c.KeepTime {
+		hasTime := false
+		for _, f := range c.DateFormats {
+			hasTime = hasTime || f == util.DateFormatExcel || util.LayoutHasTime(f)
+		}
+		if !hasTime {
+			add("csv.keepTime", "needs a date format with time of day, e.g. \"2006-01-02 15:04:05\"")
+		}
+	}
+
+	switch c.Decimal {
+	case "de", "en":
+	default:
+		add("csv.decimal", "unknown mode %q (want de or en)", c.Decimal)
+	}
+	switch c.ThousandsSep {
+	// any space character groups digits, and "'" covers ’ as well
+	case "", ".", ",", " ", "\u00a0", "\u202f", "\u2009", "'", "’":
+		if dec := map[string]string{"de": ",", "en": "."}[c.Decimal]; dec != "" && c.ThousandsSep == dec {
+			add("csv.thousandsSep", "must differ from the decimal separator %q", dec)
+		}
+	default:
+		add("csv.thousandsSep", "unsupported separator %q", c.ThousandsSep)
+	}
+
+	errs = append(errs, checkColumns(c)...)
+	errs = append(errs, checkTransforms(c.Columns)...)
+	errs = append(errs, checkRowFilters(c)...)
+	if len(c.PendingValues) > 0 && strings.TrimSpace(c.Columns.Status) == "" {
+		add("csv.pendingValues", "needs columns.status")
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// checkLayout round-trips a probe date through layout f. The year may be
+// missing (it is inferred on import) or have two digits.
+func checkLayout(f string) string {
+	if strings.TrimSpace(f) == "" {
+		return "empty layout"
+	}
+	if f == util.DateFormatExcel {
+		return ""
+	}
+	p, err := util.ParseDate(probeDate.Format(f), util.DateOptions{Formats: []string{f}})
+	if err != nil {
+		return fmt.Sprintf("invalid Go layout %q", f)
+	}
+	if p.Date.Month() != probeDate.Month() || p.Date.Day() != probeDate.Day() ||
+		p.HasYear && p.Date.Year() != probeDate.Year() {
+		return fmt.Sprintf("layout %q must contain month and day (reference date is 02.01.2006)", f)
+	}
+	return ""
+}
+
+func checkColumns(c domain.CSVTemplate) []FieldError {
+	var errs []FieldError
+	cols := c.Columns
+	named := []struct{ field, col string }{
+		{"bookingDate", cols.BookingDate},
+		{"valueDate", cols.ValueDate},
+		{"amount", cols.Amount},
+		{"currency", cols.Currency},
+		{"payee", cols.Payee},
+		{"memo", cols.Memo},
+		{"reference", cols.Reference},
+		{"iban", cols.Iban},
+		{"balance", cols.Balance},
+		{"status", cols.Status},
+	}
+	for i, m := range cols.MemoFields {
+		named = append(named, struct{ field, col string }{fmt.Sprintf("memoFields[%d]", i), m})
+	}
+
+	if strings.TrimSpace(cols.BookingDate) == "" {
+		errs = append(errs, FieldError{"csv.columns.bookingDate", "required"})
+	}
+	if strings.TrimSpace(cols.Amount) == "" {
+		errs = append(errs, FieldError{"csv.columns.amount", "required"})
+	}
+	if cols.Memo != "" && len(cols.MemoFields) > 0 {
+		errs = append(errs, FieldError{"csv.columns.memo", "set either memo or memoFields, not both"})
+	}
+
+	// banks put the same column behind several fields (booking and value
+	// date, payee and memo), so only a repeated memo part is a mistake
+	seen := map[string]string{}
+	for _, n := range named {
+		col := strings.TrimSpace(n.col)
+		if col == "" {
+			if strings.HasPrefix(n.field, "memoFields") {
+				errs = append(errs, FieldError{"csv.columns." + n.field, "empty column name"})
+			}
+			continue
+		}
+		if !c.HasHeader && !noHdrCol.MatchString(col) {
+			errs = append(errs, FieldError{"csv.columns." + n.field, fmt.Sprintf("without a header row columns are named col_0, col_1, ...; got %q", col)})
+		}
+		if !strings.HasPrefix(n.field, "memoFields") {
+			continue
+		}
+		if prev, dup := seen[col]; dup {
+			errs = append(errs, FieldError{"csv.columns." + n.field, fmt.Sprintf("column %q is already used for %s", col, prev)})
+			continue
+		}
+		seen[col] = n.field
+	}
+	return errs
+}
+
+func checkTransforms(cols domain.CSVColumns) []FieldError {
+	var errs []FieldError
+	for field, steps := range cols.Transforms {
+		path := "csv.columns.transforms." + field
+		if _, err := transform.CompileAll(domain.CSVColumns{Transforms: map[string][]domain.Transform{field: steps}}); err != nil {
+			var se *transform.StepError
+			if errors.As(err, &se) {
+				errs = append(errs, FieldError{fmt.Sprintf("%s[%d]", path, se.Index), se.Message})
+			} else {
+				errs = append(errs, FieldError{path, "unknown field"})
+			}
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+func checkRowFilters(c domain.CSVTemplate) []FieldError {
+	var errs []FieldError
+	for _, l := range []struct {
+		name  string
+		preds []domain.RowPredicate
+	}{{"include", c.Include}, {"exclude", c.Exclude}, {"endOfData", c.EndOfData}} {
+		for i, p := range l.preds {
+			path := fmt.Sprintf("csv.%s[%d]", l.name, i)
+			if err := rowfilter.Check(p); err != nil {
+				errs = append(errs, FieldError{path, err.Error()})
+			}
+			if col := strings.TrimSpace(p.Column); col != "" && !c.HasHeader && !noHdrCol.MatchString(col) {
+				errs = append(errs, FieldError{path + ".column", fmt.Sprintf("without a header row columns are named col_0, col_1, ...; got %q", col)})
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,107 @@
+package template
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"bankdash/backend/internal/domain"
+)
+
+func validTemplate() domain.BankTemplate {
+	return domain.BankTemplate{
+		SchemaVersion: domain.TemplateSchemaVersion,
+		ID:            "test-de-csv",
+		Type:          "csv",
+		CSV: domain.CSVTemplate{
+			Delimiter:   ";",
+			HasHeader:   true,
+			DateFormats: []string{"02.01.2006"},
+			Decimal:     "de",
+			Columns: domain.CSVColumns{
+				BookingDate: "Buchungstag",
+				Amount:      "Betrag",
+				Payee:       "Empfänger",
+			},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*domain.BankTemplate)
+		fields []string // expected FieldError.Field values, in order; nil = valid
+	}{
+		{"valid", func(*domain.BankTemplate) {}, nil},
+		{"booking and value date share a column", func(t *domain.BankTemplate) {
+			t.CSV.Columns.ValueDate = "Buchungstag"
+		}, nil},
+		{"payee and memo share a column", func(t *domain.BankTemplate) {
+			t.CSV.Columns.Memo = "Empfänger"
+		}, nil},
+		{"memo part repeats another field", func(t *domain.BankTemplate) {
+			t.CSV.Columns.MemoFields = []string{"Empfänger", "Verwendungszweck"}
+		}, nil},
+		{"memo part repeats itself", func(t *domain.BankTemplate) {
+			t.CSV.Columns.MemoFields = []string{"Verwendungszweck", "Verwendungszweck"}
+		}, []string{"csv.columns.memoFields[1]"}},
+		{"missing required columns", func(t *domain.BankTemplate) {
+			t.CSV.Columns.BookingDate = ""
+			t.CSV.Columns.Amount = " "
+		}, []string{"csv.columns.bookingDate", "csv.columns.amount"}},
+		{"header names without a header row", func(t *domain.BankTemplate) {
+			t.CSV.HasHeader = false
+			t.CSV.Columns = domain.CSVColumns{BookingDate: "col_0", Amount: "Betrag"}
+		}, []string{"csv.columns.amount"}},
+		{"layout without day", func(t *domain.BankTemplate) {
+			t.CSV.DateFormats = []string{"02.01.2006", "01.2006"}
+		}, []string{"csv.dateFormats[1]"}},
+		{"layout without year", func(t *domain.BankTemplate) {
+			t.CSV.DateFormats = []string{"02.01."}
+		}, nil},
+		{"excel serials", func(t *domain.BankTemplate) {
+			t.CSV.DateFormats = []string{"excel"}
+			t.CSV.KeepTime = true
+		}, nil},
+		{"keepTime without a time layout", func(t *domain.BankTemplate) {
+			t.CSV.KeepTime = true
+		}, []string{"csv.keepTime"}},
+		{"bad settings", func(t *domain.BankTemplate) {
+			t.SchemaVersion = 1
+			t.ID = "no spaces"
+			t.CSV.Delimiter = ";;"
+			t.CSV.Rounding = "up"
+			t.CSV.Currency = "eur"
+			t.CSV.Timezone = "Mars/Olympus"
+			t.CSV.ThousandsSep = ","
+		}, []string{"schemaVersion", "id", "csv.delimiter", "csv.rounding", "csv.currency", "csv.timezone", "csv.thousandsSep"}},
+		{"pending values without status column", func(t *domain.BankTemplate) {
+			t.CSV.PendingValues = []string{"offen"}
+		}, []string{"csv.pendingValues"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := validTemplate()
+			tt.modify(&tmpl)
+			err := Validate(tmpl)
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var ve *ValidationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("Validate() = %v, want *ValidationError", err)
+			}
+			var got []string
+			for _, fe := range ve.Errors {
+				got = append(got, fe.Field)
+			}
+			if !slices.Equal(got, tt.fields) {
+				t.Fatalf("fields = %q, want %q", got, tt.fields)
+			}
+		})
+	}
+}
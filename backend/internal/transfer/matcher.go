@@ -0,0 +1,139 @@
+package transfer
+
+import (
+	"strings"
+	"time"
+
+	"bankdash/backend/internal/domain"
+	"bankdash/backend/internal/util"
+)
+
+// Matcher pairs the two legs of a transfer between registered accounts:
+// opposite amounts, booked within Window of each other, and one side naming
+// the other account (by IBAN, or by account/holder name as payee).
+type Matcher struct {
+	accounts map[string]domain.Account
+	window   time.Duration
+}
+
+func NewMatcher(accounts []domain.Account, windowDays int) *Matcher {
+	m := &Matcher{accounts: map[string]domain.Account{}, window: time.Duration(windowDays) * 24 * time.Hour}
+	for _, a := range accounts {
+		m.accounts[a.ID] = a
+	}
+	return m
+}
+
+// Registered reports whether accountID is one of our own accounts.
+func (m *Matcher) Registered(accountID string) bool {
+	_, ok := m.accounts[accountID]
+	return ok
+}
+
+// OtherAccounts lists the registered account ids except accountID.
+func (m *Matcher) OtherAccounts(accountID string) []string {
+	var out []string
+	for id := range m.accounts {
+		if id != accountID {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// Window is the maximum booking date distance between two legs.
+func (m *Matcher) Window() time.Duration { return m.window }
+
+// Match links legs in batch with legs in stored (already imported, other
+// accounts). Both slices are updated in place; the returned indexes point at
+// stored legs that changed and need to be written back.
+func (m *Matcher) Match(batch, stored []domain.Transaction) []int {
+	taken := map[int]bool{}
+	var changed []int
+	for bi := range batch {
+		a := &batch[bi]
+		if a.AmountCents == 0 || !m.Registered(a.AccountID) {
+			continue
+		}
+		best := -1
+		var bestDist time.Duration
+		for si := range stored {
+			b := &stored[si]
+			if taken[si] || !m.isPair(a, b) {
+				continue
+			}
+			d := util.AbsDuration(a.BookingDate.Sub(b.BookingDate))
+			if movesLink(a, b) {
+				d = -1
+			}
+			if best < 0 || d < bestDist {
+				best, bestDist = si, d
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		taken[best] = true
+		b := &stored[best]
+		link(a, b)
+		if b.TransferPeerUID != a.TxUID || b.CategoryID != domain.CategoryTransfer {
+			changed = append(changed, best)
+		}
+		link(b, a)
+	}
+	return changed
+}
+
+func (m *Matcher) isPair(a, b *domain.Transaction) bool {
+	if a.AccountID == b.AccountID || a.AmountCents != -b.AmountCents || a.Currency != b.Currency {
+		return false
+	}
+	if movesLink(a, b) {
+		return true
+	}
+	if b.TransferPeerUID != "" && b.TransferPeerUID != a.TxUID {
+		return false
+	}
+	if util.AbsDuration(a.BookingDate.Sub(b.BookingDate)) > m.window {
+		return false
+	}
+	accA, okA := m.accounts[a.AccountID]
+	accB, okB := m.accounts[b.AccountID]
+	if !okA || !okB {
+		return false
+	}
+	return names(a, accB) || names(b, accA)
+}
+
+// names reports whether tx's counterparty is acc.
+func names(tx *domain.Transaction, acc domain.Account) bool {
+	if iban := normIBAN(tx.IBAN); iban != "" && iban == normIBAN(acc.IBAN) {
+		return true
+	}
+	payee := util.NormName(tx.Payee)
+	if payee == "" {
+		return false
+	}
+	for _, n := range append([]string{acc.Name}, acc.HolderNames...) {
+		if n := util.NormName(n); n != "" && payee == n {
+			return true
+		}
+	}
+	return false
+}
+
+// movesLink reports whether b is linked to the pending booking a settles; a
+// takes over that link whatever its date, since the pending one is deleted.
+func movesLink(a, b *domain.Transaction) bool {
+	return a.ReplacesUID != "" && b.TransferPeerUID == a.ReplacesUID
+}
+
+func link(tx, peer *domain.Transaction) {
+	tx.CategoryID = domain.CategoryTransfer
+	tx.TransferPeerUID = peer.TxUID
+	tx.TransferAccountID = peer.AccountID
+}
+
+func normIBAN(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
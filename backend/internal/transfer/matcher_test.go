@@ -0,0 +1,66 @@
+package transfer
+
+import (
+	"testing"
+	"time"
+
+	"bankdash/backend/internal/domain"
+)
+
+var accounts = []domain.Account{
+	{ID: "giro", Name: "Girokonto", IBAN: "DE02 1203 0000 0000 2020 51"},
+	{ID: "savings", Name: "Tagesgeld", IBAN: "DE02 5001 0517 0137 5063 46"},
+}
+
+func day(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+
+func TestMatch(t *testing.T) {
+	out := domain.Transaction{TxUID: "out", AccountID: "giro", AmountCents: -50000, Currency: "EUR",
+		BookingDate: day(5), IBAN: "DE02500105170137506346"}
+	in := domain.Transaction{TxUID: "in", AccountID: "savings", AmountCents: 50000, Currency: "EUR",
+		BookingDate: day(6), Payee: "Girokonto"}
+
+	batch, stored := []domain.Transaction{out}, []domain.Transaction{in}
+	changed := NewMatcher(accounts, 3).Match(batch, stored)
+	if len(changed) != 1 || batch[0].TransferPeerUID != "in" || stored[0].TransferPeerUID != "out" ||
+		batch[0].CategoryID != domain.CategoryTransfer || stored[0].TransferAccountID != "giro" {
+		t.Fatalf("not linked: batch %+v, stored %+v, changed %v", batch[0], stored[0], changed)
+	}
+
+	// a re-import finds the link in place and changes nothing
+	batch = []domain.Transaction{out}
+	if changed := NewMatcher(accounts, 3).Match(batch, stored); len(changed) != 0 || batch[0].TransferPeerUID != "in" {
+		t.Fatalf("re-import: changed %v, peer %q", changed, batch[0].TransferPeerUID)
+	}
+
+	// too far apart
+	far := in
+	far.BookingDate = day(12)
+	batch, stored = []domain.Transaction{out}, []domain.Transaction{far}
+	if changed := NewMatcher(accounts, 3).Match(batch, stored); len(changed) != 0 || batch[0].TransferPeerUID != "" {
+		t.Fatalf("linked legs 7 days apart")
+	}
+}
+
+func TestMatchMovesLinkFromPending(t *testing.T) {
+	// the savings leg was linked to the pending version of the giro leg;
+	// the booked version, dated later and with another payee, takes it over
+	peer := domain.Transaction{TxUID: "in", AccountID: "savings", AmountCents: 50000, Currency: "EUR",
+		BookingDate: day(2), CategoryID: domain.CategoryTransfer, TransferPeerUID: "pending-out", TransferAccountID: "giro"}
+	booked := domain.Transaction{TxUID: "booked-out", AccountID: "giro", AmountCents: -50000, Currency: "EUR",
+		BookingDate: day(8), ReplacesUID: "pending-out"}
+	other := domain.Transaction{TxUID: "in2", AccountID: "savings", AmountCents: 50000, Currency: "EUR",
+		BookingDate: day(8), Payee: "Girokonto"}
+
+	batch, stored := []domain.Transaction{booked}, []domain.Transaction{other, peer}
+	changed := NewMatcher(accounts, 3).Match(batch, stored)
+	if batch[0].TransferPeerUID != "in" || batch[0].CategoryID != domain.CategoryTransfer {
+		t.Fatalf("booked leg peer = %q, want in", batch[0].TransferPeerUID)
+	}
+	if len(changed) != 1 || changed[0] != 1 || stored[1].TransferPeerUID != "booked-out" {
+		t.Fatalf("peer not relinked: changed %v, peer %+v", changed, stored[1])
+	}
+	if stored[0].TransferPeerUID != "" {
+		t.Fatalf("closer unlinked leg was taken instead")
+	}
+}
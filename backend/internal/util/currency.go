@@ -0,0 +1,46 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ISO 4217 minor unit exponents that differ from the usual 2.
+var minorUnits = map[string]int{
+	// no minor unit
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	// thousandths
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	// unidades de fomento
+	"CLF": 4, "UYW": 4,
+}
+
+// MinorUnits is the ISO 4217 exponent of currency: amounts are stored as
+// integers of 10^-MinorUnits. Unknown codes get 2.
+func MinorUnits(currency string) int {
+	if n, ok := minorUnits[strings.ToUpper(strings.TrimSpace(currency))]; ok {
+		return n
+	}
+	return 2
+}
+
+// FormatMinor renders minor units of currency as a plain decimal ("-1234.50",
+// "-1234,50", JPY "-1234"), without thousands separators. Totals without a
+// currency ("") get two decimals.
+func FormatMinor(minor int64, currency, decimal string) string {
+	exp := MinorUnits(currency)
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	if exp == 0 {
+		return fmt.Sprintf("%s%d", sign, minor)
+	}
+	div := int64(1)
+	for i := 0; i < exp; i++ {
+		div *= 10
+	}
+	return fmt.Sprintf("%s%d%s%0*d", sign, minor/div, decimal, exp, minor%div)
+}
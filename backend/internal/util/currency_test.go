@@ -0,0 +1,47 @@
+package util
+
+import "testing"
+
+func TestMinorUnits(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     int
+	}{
+		{"EUR", 2},
+		{"", 2},
+		{"XYZ", 2},
+		{"JPY", 0},
+		{" jpy ", 0},
+		{"KWD", 3},
+		{"CLF", 4},
+	}
+	for _, tt := range tests {
+		if got := MinorUnits(tt.currency); got != tt.want {
+			t.Errorf("MinorUnits(%q) = %d, want %d", tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestFormatMinor(t *testing.T) {
+	tests := []struct {
+		minor    int64
+		currency string
+		decimal  string
+		want     string
+	}{
+		{-123450, "EUR", ".", "-1234.50"},
+		{-123450, "EUR", ",", "-1234,50"},
+		{5, "EUR", ".", "0.05"},
+		{-5, "", ".", "-0.05"},
+		{0, "EUR", ".", "0.00"},
+		{-1234, "JPY", ".", "-1234"},
+		{0, "JPY", ".", "0"},
+		{1500, "KWD", ".", "1.500"},
+		{-1, "KWD", ",", "-0,001"},
+	}
+	for _, tt := range tests {
+		if got := FormatMinor(tt.minor, tt.currency, tt.decimal); got != tt.want {
+			t.Errorf("FormatMinor(%d, %q, %q) = %q, want %q", tt.minor, tt.currency, tt.decimal, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package util
+
+import (
+	"strings"
+	"time"
+)
+
+// NormName folds a payee or account holder name for comparison: lower case,
+// runs of whitespace collapsed to one space.
+func NormName(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// AbsDuration is |d|, e.g. the distance between two booking dates.
+func AbsDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -2,29 +2,87 @@ package util
 
 import (
 	"fmt"
-	"strconv"
+	"math/big"
+	"regexp"
 	"strings"
+	"unicode"
 )
 
-func ParseAmountCents(s, decimalMode, thousandsSep string) (int64, error) {
-	s = strings.TrimSpace(s)
+// Decimal is an exactly parsed amount: coef * 10^-scale.
+type Decimal struct {
+	coef  *big.Int
+	scale int
+}
+
+// RoundingMode decides how digits beyond a currency's minor unit are dropped.
+type RoundingMode string
+
+const (
+	RoundHalfUp   RoundingMode = "halfUp"   // 0.005 -> 0.01, -0.005 -> -0.01 (default)
+	RoundHalfEven RoundingMode = "halfEven" // banker's rounding: 0.005 -> 0.00, 0.015 -> 0.02
+	RoundDown     RoundingMode = "down"     // truncate toward zero
+)
+
+func (m RoundingMode) Valid() bool {
+	switch m {
+	case "", RoundHalfUp, RoundHalfEven, RoundDown:
+		return true
+	}
+	return false
+}
+
+// dashes that exports use in place of "-"
+var minusSigns = strings.NewReplacer("\u2212", "-", "\u2012", "-", "\u2013", "-", "\ufe63", "-", "\uff0d", "-")
+
+// symbols in amount cells and the currency they name; "$" and "¥" stand for
+// several (USD, CAD, AUD, ...; JPY, CNY), so they are stripped but name none
+var currencySymbols = map[string]string{"€": "EUR", "£": "GBP", "$": "", "¥": "", "₿": ""}
+
+var currencyCodeRe = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ParseAmount reads a bank amount like "-1.234,5678" (de) or "1,234.56" (en)
+// without losing digits. It also accepts "(28,95)" and "28,95-" as
+// negatives, unicode minus signs, a currency symbol or code before or after
+// the number ("€ -28,95", "-28.95 EUR"), and any kind of space as
+// thousands separator; with thousandsSep "'" the typographic ’ works too.
+func ParseAmount(s, decimalMode, thousandsSep string) (Decimal, error) {
+	s, _ = stripCurrency(minusSigns.Replace(strings.TrimSpace(s)))
 	if s == "" {
-		return 0, fmt.Errorf("empty amount")
+		return Decimal{}, fmt.Errorf("empty amount")
 	}
 
 	neg := false
-	if strings.HasPrefix(s, "-") {
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
 		neg = true
-		s = strings.TrimPrefix(s, "-")
+		s, _ = stripCurrency(s[1 : len(s)-1])
 	}
-	if strings.HasPrefix(s, "+") {
-		s = strings.TrimPrefix(s, "+")
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg, s = true, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	case strings.HasSuffix(s, "-"):
+		neg, s = true, s[:len(s)-1]
+	case strings.HasSuffix(s, "+"):
+		s = s[:len(s)-1]
 	}
-	s = strings.TrimSpace(s)
+	// "-€28,95"
+	s, _ = stripCurrency(s)
+
+	// spaces only ever group digits; NBSP and thin spaces included
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
 
 	// remove thousands separators
-	if thousandsSep != "" {
-		s = strings.ReplaceAll(s, thousandsSep, "")
+	if thousandsSep == "'" || thousandsSep == "’" {
+		s = strings.ReplaceAll(strings.ReplaceAll(s, "’", ""), "'", "")
+	}
+	if sep := strings.TrimSpace(thousandsSep); sep != "" {
+		s = strings.ReplaceAll(s, sep, "")
 	}
 
 	// decimal comma for DE
@@ -32,40 +90,148 @@ func ParseAmountCents(s, decimalMode, thousandsSep string) (int64, error) {
 		s = strings.ReplaceAll(s, ",", ".")
 	}
 
-	// now s is like "28.95" or "28" or "28.9"
+	// now s is like "28.95" or "28" or "0.00012345"
 	wholePart := s
 	fracPart := ""
 	if dot := strings.IndexByte(s, '.'); dot >= 0 {
 		wholePart = s[:dot]
 		fracPart = s[dot+1:]
 	}
-
 	if wholePart == "" {
 		wholePart = "0"
 	}
+	if !isDigits(wholePart) {
+		return Decimal{}, fmt.Errorf("invalid whole part: %q", wholePart)
+	}
+	if !isDigits(fracPart) {
+		return Decimal{}, fmt.Errorf("invalid fractional part: %q", fracPart)
+	}
 
-	whole, err := strconv.ParseInt(wholePart, 10, 64)
-	if err != nil {
-		return 0, fmt.Errorf("invalid whole part: %q", wholePart)
+	coef, _ := new(big.Int).SetString(wholePart+fracPart, 10)
+	if neg {
+		coef.Neg(coef)
 	}
+	return Decimal{coef: coef, scale: len(fracPart)}, nil
+}
+
+// AmountCurrency returns the ISO 4217 code an amount cell names by symbol or
+// code, e.g. "EUR" for "-28,95 €"; "" when there is none or the symbol is
+// ambiguous, like "$".
+func AmountCurrency(s string) string {
+	s = strings.TrimSpace(minusSigns.Replace(s))
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "("), ")")
+	s = strings.Trim(s, "+-")
+	_, code := stripCurrency(s)
+	return code
+}
 
-	// normalize fractional to 2 digits
-	if fracPart == "" {
-		fracPart = "00"
-	} else if len(fracPart) == 1 {
-		fracPart = fracPart + "0"
-	} else if len(fracPart) > 2 {
-		fracPart = fracPart[:2]
+// stripCurrency removes one currency symbol or code from either end of s.
+func stripCurrency(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	for sym, code := range currencySymbols {
+		if strings.HasPrefix(s, sym) {
+			return strings.TrimSpace(strings.TrimPrefix(s, sym)), code
+		}
+		if strings.HasSuffix(s, sym) {
+			return strings.TrimSpace(strings.TrimSuffix(s, sym)), code
+		}
+	}
+	if len(s) > 3 && currencyCodeRe.MatchString(s[:3]) {
+		return strings.TrimSpace(s[3:]), s[:3]
+	}
+	if len(s) > 3 && currencyCodeRe.MatchString(s[len(s)-3:]) {
+		return strings.TrimSpace(s[:len(s)-3]), s[len(s)-3:]
 	}
+	return s, ""
+}
 
-	frac, err := strconv.ParseInt(fracPart, 10, 64)
+// ParseAmountMinor parses s and converts it to minor units of currency.
+func ParseAmountMinor(s, decimalMode, thousandsSep, currency string, mode RoundingMode) (int64, error) {
+	d, err := ParseAmount(s, decimalMode, thousandsSep)
 	if err != nil {
-		return 0, fmt.Errorf("invalid fractional part: %q", fracPart)
+		return 0, err
 	}
+	return d.Minor(MinorUnits(currency), mode)
+}
 
-	cents := whole*100 + frac
-	if neg {
-		cents = -cents
+// Scale is the number of fractional digits as written.
+func (d Decimal) Scale() int { return d.scale }
+
+// Minor returns d in units of 10^-exp, rounded with mode.
+func (d Decimal) Minor(exp int, mode RoundingMode) (int64, error) {
+	if d.coef == nil {
+		return 0, nil
+	}
+	v := new(big.Int).Set(d.coef)
+	if d.scale <= exp {
+		v.Mul(v, pow10(exp-d.scale))
+	} else {
+		q, r := new(big.Int).QuoRem(v, pow10(d.scale-exp), new(big.Int))
+		// compare the dropped part with one half, on absolute values
+		half := new(big.Int).Abs(r)
+		half.Mul(half, big.NewInt(2))
+		cmp := half.Cmp(pow10(d.scale - exp))
+		up := false
+		switch mode {
+		case RoundDown:
+		case RoundHalfEven:
+			up = cmp > 0 || cmp == 0 && q.Bit(0) == 1
+		default:
+			up = cmp >= 0
+		}
+		if up {
+			if v.Sign() < 0 {
+				q.Sub(q, big.NewInt(1))
+			} else {
+				q.Add(q, big.NewInt(1))
+			}
+		}
+		v = q
+	}
+	if !v.IsInt64() {
+		return 0, fmt.Errorf("amount out of range: %s", d)
+	}
+	return v.Int64(), nil
+}
+
+// Exact reports whether d fits exp fractional digits without rounding.
+func (d Decimal) Exact(exp int) bool {
+	if d.coef == nil || d.scale <= exp {
+		return true
+	}
+	r := new(big.Int).Rem(d.coef, pow10(d.scale-exp))
+	return r.Sign() == 0
+}
+
+// String renders d with "." as decimal point and no trailing zeros.
+func (d Decimal) String() string {
+	if d.coef == nil {
+		return "0"
+	}
+	digits := new(big.Int).Abs(d.coef).String()
+	if d.scale > 0 {
+		if len(digits) <= d.scale {
+			digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+		}
+		cut := len(digits) - d.scale
+		digits = strings.TrimRight(digits[:cut]+"."+digits[cut:], "0")
+		digits = strings.TrimSuffix(digits, ".")
+	}
+	if d.coef.Sign() < 0 {
+		return "-" + digits
 	}
-	return cents, nil
+	return digits
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
 }
@@ -0,0 +1,145 @@
+package util
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in, dec, sep string
+		want         string
+	}{
+		{"-1.234,5678", "de", ".", "-1234.5678"},
+		{"1,234.56", "en", ",", "1234.56"},
+		{"28,90", "de", ".", "28.9"},
+		{"+0,00012345", "de", ".", "0.00012345"},
+		{"(28,95)", "de", ".", "-28.95"},
+		{"28,95-", "de", ".", "-28.95"},
+		{"−28,95", "de", ".", "-28.95"},
+		{"€ -28,95", "de", ".", "-28.95"},
+		{"-28.95 EUR", "en", ",", "-28.95"},
+		{"1 234,50", "de", ".", "1234.5"},
+		{"1’234.50", "en", "'", "1234.5"},
+		{",5", "de", ".", "0.5"},
+	}
+	for _, tt := range tests {
+		d, err := ParseAmount(tt.in, tt.dec, tt.sep)
+		if err != nil {
+			t.Errorf("ParseAmount(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got := d.String(); got != tt.want {
+			t.Errorf("ParseAmount(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "abc", "1,2,3", "12,3x"} {
+		if _, err := ParseAmount(in, "de", "."); err == nil {
+			t.Errorf("ParseAmount(%q) = nil error, want one", in)
+		}
+	}
+}
+
+func TestDecimalMinor(t *testing.T) {
+	tests := []struct {
+		in   string // en notation
+		exp  int
+		mode RoundingMode
+		want int64
+	}{
+		// ties, both signs
+		{"0.005", 2, RoundHalfUp, 1},
+		{"-0.005", 2, RoundHalfUp, -1},
+		{"0.015", 2, RoundHalfUp, 2},
+		{"0.005", 2, RoundHalfEven, 0},
+		{"-0.005", 2, RoundHalfEven, 0},
+		{"0.015", 2, RoundHalfEven, 2},
+		{"-0.015", 2, RoundHalfEven, -2},
+		{"0.025", 2, RoundHalfEven, 2},
+		{"-0.025", 2, RoundHalfEven, -2},
+		{"0.009", 2, RoundDown, 0},
+		{"-0.009", 2, RoundDown, 0},
+		{"-1.999", 2, RoundDown, -199},
+		// away from ties
+		{"0.0051", 2, RoundHalfEven, 1},
+		{"-0.0049", 2, RoundHalfUp, 0},
+		{"", 2, "", 0},
+		// default mode is half up
+		{"2.345", 2, "", 235},
+		// JPY: no minor unit
+		{"1234", 0, RoundHalfUp, 1234},
+		{"1234.5", 0, RoundHalfUp, 1235},
+		{"-1234.5", 0, RoundHalfUp, -1235},
+		{"1234.5", 0, RoundHalfEven, 1234},
+		{"1235.5", 0, RoundHalfEven, 1236},
+		{"-1234.5", 0, RoundDown, -1234},
+		// KWD: thousandths
+		{"1.5", 3, RoundHalfUp, 1500},
+		{"1.2345", 3, RoundHalfUp, 1235},
+		{"-1.2345", 3, RoundHalfUp, -1235},
+		{"1.2345", 3, RoundHalfEven, 1234},
+		{"-1.2345", 3, RoundDown, -1234},
+	}
+	for _, tt := range tests {
+		var d Decimal
+		if tt.in != "" {
+			var err error
+			if d, err = ParseAmount(tt.in, "en", ","); err != nil {
+				t.Fatalf("ParseAmount(%q): %v", tt.in, err)
+			}
+		}
+		got, err := d.Minor(tt.exp, tt.mode)
+		if err != nil {
+			t.Errorf("%s.Minor(%d, %q) error: %v", tt.in, tt.exp, tt.mode, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s.Minor(%d, %q) = %d, want %d", tt.in, tt.exp, tt.mode, got, tt.want)
+		}
+	}
+
+	d, _ := ParseAmount("100000000000000000000", "en", ",")
+	if _, err := d.Minor(2, RoundHalfUp); err == nil {
+		t.Errorf("Minor of %s = nil error, want out of range", d)
+	}
+}
+
+func TestDecimalExact(t *testing.T) {
+	tests := []struct {
+		in   string
+		exp  int
+		want bool
+	}{
+		{"28.95", 2, true},
+		{"28.950", 2, true},
+		{"28.951", 2, false},
+		{"1234", 0, true},
+		{"1234.5", 0, false},
+		{"1.234", 3, true},
+	}
+	for _, tt := range tests {
+		d, err := ParseAmount(tt.in, "en", ",")
+		if err != nil {
+			t.Fatalf("ParseAmount(%q): %v", tt.in, err)
+		}
+		if got := d.Exact(tt.exp); got != tt.want {
+			t.Errorf("%s.Exact(%d) = %v, want %v", tt.in, tt.exp, got, tt.want)
+		}
+	}
+}
+
+func TestAmountCurrency(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"-28,95 €", "EUR"},
+		{"€-28,95", "EUR"},
+		{"(£12.00)", "GBP"},
+		{"-28.95 USD", "USD"},
+		{"CHF 1'234.50", "CHF"},
+		{"28,95", ""},
+		{"$12.00", ""},
+		{"-1234 ¥", ""},
+	}
+	for _, tt := range tests {
+		if got := AmountCurrency(tt.in); got != tt.want {
+			t.Errorf("AmountCurrency(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -2,23 +2,162 @@ package util
 
 import (
 	"fmt"
+	"math"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
-func ParseDate(s string, formats []string, loc *time.Location) (time.Time, error) {
+// DateFormatExcel in a template's dateFormats reads spreadsheet serial day
+// numbers ("45678", "45678.5" with time of day) as exported from XLSX.
+const DateFormatExcel = "excel"
+
+// DateOptions control ParseDate beyond plain Go layouts.
+type DateOptions struct {
+	Formats []string       // Go layouts, or DateFormatExcel
+	Loc     *time.Location // nil means UTC
+	// two-digit years ("06" layouts) below YearPivot are 20xx, the others
+	// 19xx; 0 means 70
+	YearPivot int
+}
+
+// ParsedDate is one parsed date cell.
+type ParsedDate struct {
+	Date    time.Time  // midnight in Loc; year 0 unless HasYear
+	Time    *time.Time // the exact instant, when the cell carries a time of day
+	HasYear bool
+}
+
+// excel's day 0 is 1899-12-31, but it counts a 1900-02-29 that never was,
+// so from day 61 on this base gives the right date
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// German month names and abbreviations, folded onto Go's English "Jan"
+var monthNames = map[string]string{
+	"januar": "Jan", "jänner": "Jan", "jän": "Jan", "january": "Jan", "jan": "Jan",
+	"februar": "Feb", "february": "Feb", "feb": "Feb",
+	"märz": "Mar", "maerz": "Mar", "mär": "Mar", "mrz": "Mar", "march": "Mar", "mar": "Mar",
+	"april": "Apr", "apr": "Apr",
+	"mai": "May", "may": "May",
+	"juni": "Jun", "june": "Jun", "jun": "Jun",
+	"juli": "Jul", "july": "Jul", "jul": "Jul",
+	"august": "Aug", "aug": "Aug",
+	"september": "Sep", "sept": "Sep", "sep": "Sep",
+	"oktober": "Oct", "october": "Oct", "okt": "Oct", "oct": "Oct",
+	"november": "Nov", "nov": "Nov",
+	"dezember": "Dec", "december": "Dec", "dez": "Dec", "dec": "Dec",
+}
+
+var wordRe = regexp.MustCompile(`\p{L}+\.?`)
+
+// ParseDate tries o.Formats in order. Layouts naming the month ("Jan" or
+// "January") also accept German names and abbreviations with or without a
+// dot ("23. Dez. 2025" for "02. Jan 2006"). The time of day is returned
+// separately, so Date stays a calendar day.
+func ParseDate(s string, o DateOptions) (ParsedDate, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
-		return time.Time{}, fmt.Errorf("empty date")
+		return ParsedDate{}, fmt.Errorf("empty date")
 	}
+	loc := o.Loc
 	if loc == nil {
 		loc = time.UTC
 	}
-	for _, f := range formats {
-		if t, err := time.ParseInLocation(f, s, loc); err == nil {
-			// normalize to midnight local time for deterministic day grouping
-			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc), nil
+	for _, f := range o.Formats {
+		if f == DateFormatExcel {
+			if p, ok := parseExcel(s, loc); ok {
+				return p, nil
+			}
+			continue
 		}
+		layout, value := f, s
+		if strings.Contains(f, "Jan") {
+			layout, value = foldMonths(f), foldMonths(s)
+		}
+		t, err := time.ParseInLocation(layout, value, loc)
+		if err != nil {
+			continue
+		}
+		p := ParsedDate{HasYear: LayoutHasYear(f)}
+		if twoDigitYear(f) {
+			t = t.AddDate(pivotYear(t.Year()%100, o.YearPivot)-t.Year(), 0, 0)
+		}
+		if LayoutHasTime(f) {
+			exact := t
+			p.Time = &exact
+			// a stated offset may put the instant on another day in loc
+			t = t.In(loc)
+		}
+		// normalize to midnight local time for deterministic day grouping
+		p.Date = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		return p, nil
 	}
-	return time.Time{}, fmt.Errorf("unsupported date format %q (tried %v)", s, formats)
+	return ParsedDate{}, fmt.Errorf("unsupported date format %q (tried %v)", s, o.Formats)
+}
+
+// InferYear places d, parsed without a year, in the year that makes it the
+// latest date not more than a month after ref, the end of the statement.
+func InferYear(d, ref time.Time) time.Time {
+	t := time.Date(ref.Year(), d.Month(), d.Day(), d.Hour(), d.Minute(), d.Second(), d.Nanosecond(), d.Location())
+	if t.After(ref.AddDate(0, 1, 0)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t
+}
+
+// LayoutHasYear reports whether layout f (or DateFormatExcel) carries a year.
+func LayoutHasYear(f string) bool {
+	return f == DateFormatExcel || strings.Contains(f, "06")
+}
+
+// LayoutHasTime reports whether layout f carries a time of day; minutes
+// ("04") are the one token every time layout has.
+func LayoutHasTime(f string) bool {
+	return strings.Contains(f, "04")
+}
+
+func twoDigitYear(f string) bool {
+	return strings.Contains(f, "06") && !strings.Contains(f, "2006")
+}
+
+func pivotYear(yy, pivot int) int {
+	if pivot <= 0 {
+		pivot = 70
+	}
+	if yy < pivot {
+		return 2000 + yy
+	}
+	return 1900 + yy
+}
+
+func parseExcel(s string, loc *time.Location) (ParsedDate, bool) {
+	v, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", "."), 64)
+	// 1 is 1900-01-01, 2958465 is 9999-12-31
+	if err != nil || v < 1 || v > 2958465 {
+		return ParsedDate{}, false
+	}
+	days, frac := math.Modf(v)
+	d := excelEpoch.AddDate(0, 0, int(days))
+	p := ParsedDate{Date: time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc), HasYear: true}
+	if frac > 0 {
+		// spreadsheets store wall-clock time, so build it from the clock
+		// fields: adding a duration to midnight is off by an hour on DST days;
+		// a fraction a hair below 1 rounds to 86400, which stays on this day
+		sec := min(int(math.Round(frac*86400)), 86399)
+		t := time.Date(d.Year(), d.Month(), d.Day(), sec/3600, sec/60%60, sec%60, 0, loc)
+		p.Time = &t
+	}
+	return p, true
+}
+
+// foldMonths rewrites month names to Go's "Jan" form, dropping a trailing
+// abbreviation dot, in layouts and values alike.
+func foldMonths(s string) string {
+	return wordRe.ReplaceAllStringFunc(s, func(w string) string {
+		if m, ok := monthNames[strings.ToLower(strings.TrimSuffix(w, "."))]; ok {
+			return m
+		}
+		return w
+	})
 }
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateExcel(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		in   string
+		want string // wall clock in Berlin; time of day only when the serial has one
+	}{
+		{"45678", "2025-01-21"},
+		{"45678.5", "2025-01-21 12:00:00"},
+		// DST starts and ends: the cell holds the clock, not elapsed time
+		{"45746.5", "2025-03-30 12:00:00"},
+		{"45956.75", "2025-10-26 18:00:00"},
+		{"45746,125", "2025-03-30 03:00:00"},
+		// rounds to 24:00:00; the booking stays on its day
+		{"45678.999999999", "2025-01-21 23:59:59"},
+	}
+	for _, tt := range tests {
+		p, err := ParseDate(tt.in, DateOptions{Formats: []string{DateFormatExcel}, Loc: berlin})
+		if err != nil {
+			t.Errorf("ParseDate(%q) error: %v", tt.in, err)
+			continue
+		}
+		got := p.Date.Format("2006-01-02")
+		if p.Time != nil {
+			got = p.Time.In(berlin).Format("2006-01-02 15:04:05")
+		}
+		if got != tt.want {
+			t.Errorf("ParseDate(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}